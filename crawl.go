@@ -0,0 +1,241 @@
+package dyzone
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lauevrar77/dyzone/domain"
+	"github.com/lauevrar77/dyzone/downloader"
+)
+
+type crawlTask struct {
+	url   string
+	depth int
+}
+
+type crawlOutcome struct {
+	task          crawlTask
+	followingUrls []string
+	webResource   *domain.WebResource
+	bytes         int64
+	err           *CrawlError
+}
+
+// crawl holds the state of a single SpiderRunner.RunContext or
+// SpiderRunner.Stream call.
+// The frontier is only ever touched by the dispatching goroutine, workers
+// receive tasks and report outcomes through channels.
+type crawl struct {
+	ctx      context.Context
+	runner   SpiderRunner
+	visited  VisitedSet
+	tasks    chan crawlTask
+	outcomes chan crawlOutcome
+	pages    int
+	bytes    int64
+	errors   int
+}
+
+func newCrawl(ctx context.Context, runner SpiderRunner) *crawl {
+	visited := runner.visitedSet
+	if visited == nil {
+		visited = NewMemoryVisitedSet()
+	}
+
+	return &crawl{
+		ctx:      ctx,
+		runner:   runner,
+		visited:  visited,
+		tasks:    make(chan crawlTask),
+		outcomes: make(chan crawlOutcome),
+	}
+}
+
+// run crawls from startUrl, handing every pipelined resource and every
+// skipped failure to emit as soon as it is available. emit is always called
+// from the goroutine calling run. The returned error is the one that
+// stopped the crawl, if any.
+func (c *crawl) run(startUrl string, emit func(result Result)) error {
+	var workers sync.WaitGroup
+	for i := 0; i < c.runner.workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			c.work()
+		}()
+	}
+
+	frontier := make([]crawlTask, 0)
+	if c.visit(startUrl) {
+		frontier = append(frontier, crawlTask{url: startUrl, depth: 0})
+	}
+	inFlight := 0
+	done := c.ctx.Done()
+	var err error
+
+	for {
+		canDispatch := len(frontier) > 0 && err == nil && !c.budgetExhausted()
+		if !canDispatch && inFlight == 0 {
+			break
+		}
+
+		// A nil channel blocks forever, disabling the send case when
+		// there is nothing left to dispatch.
+		var tasks chan<- crawlTask
+		var next crawlTask
+		if canDispatch {
+			tasks = c.tasks
+			next = frontier[0]
+		}
+
+		select {
+		case <-done:
+			// Stop dispatching but wait for running tasks before returning
+			done = nil
+			if err == nil {
+				err = c.ctx.Err()
+			}
+		case tasks <- next:
+			frontier = frontier[1:]
+			inFlight++
+			c.pages++
+		case outcome := <-c.outcomes:
+			inFlight--
+			c.bytes += outcome.bytes
+
+			if outcome.err != nil {
+				if err == nil {
+					err = c.handleError(outcome.err, emit)
+				}
+				continue
+			}
+
+			if outcome.webResource != nil {
+				emit(Result{WebResource: outcome.webResource})
+			}
+
+			depth := outcome.task.depth + 1
+			if c.runner.maxDepth >= 0 && depth > c.runner.maxDepth {
+				continue
+			}
+
+			for _, request := range outcome.followingUrls {
+				if c.visit(request) {
+					frontier = append(frontier, crawlTask{url: request, depth: depth})
+				}
+			}
+		}
+	}
+
+	close(c.tasks)
+	workers.Wait()
+
+	return err
+}
+
+// visit marks url as visited and reports whether it was unknown until now.
+func (c *crawl) visit(url string) bool {
+	return c.visited.Visit(c.normalize(url))
+}
+
+// normalize returns the key of url in the VisitedSet. Urls that cannot be
+// normalized are used as is.
+func (c *crawl) normalize(url string) string {
+	normalizedUrl, err := c.runner.normalizer.Normalize(url)
+	if err != nil {
+		return url
+	}
+
+	return normalizedUrl
+}
+
+// handleError applies the error policy of the runner to a failure and
+// returns the error stopping the crawl, or nil when the crawl goes on.
+func (c *crawl) handleError(crawlError *CrawlError, emit func(result Result)) error {
+	if c.runner.errorPolicy == FailFast {
+		return crawlError
+	}
+
+	c.errors++
+	emit(Result{Err: crawlError})
+
+	if c.runner.maxErrors > 0 && c.errors >= c.runner.maxErrors {
+		return ErrTooManyErrors
+	}
+
+	return nil
+}
+
+// budgetExhausted reports whether the page or byte budget of the runner
+// forbids dispatching any further task.
+func (c *crawl) budgetExhausted() bool {
+	if c.runner.maxPages > 0 && c.pages >= c.runner.maxPages {
+		return true
+	}
+
+	if c.runner.maxBytes > 0 && c.bytes >= c.runner.maxBytes {
+		return true
+	}
+
+	return false
+}
+
+func (c *crawl) work() {
+	for task := range c.tasks {
+		c.outcomes <- c.process(task)
+	}
+}
+
+func (c *crawl) process(task crawlTask) crawlOutcome {
+	outcome := crawlOutcome{task: task}
+
+	// Run Downloader
+	webResource, err := downloader.DownloadContext(c.ctx, c.runner.downloader, task.url)
+
+	if err != nil {
+		outcome.err = &CrawlError{URL: task.url, Stage: DownloadStage, Err: err}
+		return outcome
+	}
+	outcome.bytes = int64(len(webResource.RawContent()))
+
+	// A redirection may land on a resource already fetched under its own url
+	finalUrl := c.normalize(webResource.FinalURL())
+	if finalUrl != c.normalize(task.url) && !c.visited.Visit(finalUrl) {
+		return outcome
+	}
+
+	var robots domain.RobotsDirectives
+	if c.runner.robotsMeta {
+		robots = webResource.Robots()
+	}
+
+	// Give result to spider to generate following requests and result
+	newRequests, webResource, err := c.runner.spider.OnWebResourceFetched(webResource)
+
+	if err != nil {
+		outcome.err = &CrawlError{URL: task.url, Stage: SpiderStage, Err: err}
+		return outcome
+	}
+
+	if !robots.NoFollow {
+		outcome.followingUrls = newRequests
+	}
+
+	if robots.NoIndex {
+		return outcome
+	}
+
+	// Send found resource into the management pipeline
+	if webResource != nil {
+		webResource, err = c.runner.pipeline.ManageWebResource(webResource)
+
+		if err != nil {
+			outcome.err = &CrawlError{URL: task.url, Stage: PipelineStage, Err: err}
+			return outcome
+		}
+
+		outcome.webResource = webResource
+	}
+
+	return outcome
+}
@@ -0,0 +1,72 @@
+package dyzone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CrawlStage names the step of a crawl during which an error happened.
+type CrawlStage string
+
+const (
+	DownloadStage CrawlStage = "download"
+	SpiderStage   CrawlStage = "spider"
+	PipelineStage CrawlStage = "pipeline"
+)
+
+// ErrorPolicy tells a SpiderRunner what to do when a url fails.
+type ErrorPolicy int
+
+const (
+	// FailFast stops the crawl on the first failure. This is the default.
+	FailFast ErrorPolicy = iota
+	// SkipAndRecord records the failure and carries on with the other urls.
+	SkipAndRecord
+)
+
+// ErrTooManyErrors ends a Stream whose runner recorded as many errors as
+// allowed by WithMaxErrors.
+var ErrTooManyErrors = errors.New("too many crawl errors")
+
+// CrawlError describes the failure of a single url.
+type CrawlError struct {
+	URL   string
+	Stage CrawlStage
+	Err   error
+}
+
+func (err *CrawlError) Error() string {
+	return fmt.Sprintf("%s of %s failed : %s", err.Stage, err.URL, err.Err)
+}
+
+func (err *CrawlError) Unwrap() error {
+	return err.Err
+}
+
+// CrawlErrors lists the failures recorded during a crawl run with the
+// SkipAndRecord policy. Cause is the error that stopped the crawl early,
+// ErrTooManyErrors or the error of the context, and nil when the crawl
+// went to its end.
+type CrawlErrors struct {
+	Errors []*CrawlError
+	Cause  error
+}
+
+func (errs *CrawlErrors) Error() string {
+	messages := make([]string, 0, len(errs.Errors))
+	for _, err := range errs.Errors {
+		messages = append(messages, err.Error())
+	}
+
+	message := fmt.Sprintf("%d crawl errors : %s", len(errs.Errors), strings.Join(messages, ", "))
+	if errs.Cause != nil {
+		message = fmt.Sprintf("crawl stopped : %s, %s", errs.Cause, message)
+	}
+
+	return message
+}
+
+func (errs *CrawlErrors) Unwrap() error {
+	return errs.Cause
+}
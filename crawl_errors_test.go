@@ -0,0 +1,41 @@
+package dyzone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCrawlErrorUnwrap(t *testing.T) {
+	notFound := errors.New("404")
+	crawlError := &CrawlError{URL: "http://example.com", Stage: DownloadStage, Err: notFound}
+
+	if !errors.Is(crawlError, notFound) {
+		t.Log("CrawlError should unwrap to the underlying error")
+		t.Fail()
+	}
+
+	expected := "download of http://example.com failed : 404"
+	if crawlError.Error() != expected {
+		t.Logf("%s different of %s", crawlError.Error(), expected)
+		t.Fail()
+	}
+}
+
+func TestCrawlErrorsMessage(t *testing.T) {
+	crawlErrors := &CrawlErrors{Errors: []*CrawlError{
+		{URL: "http://example.com/a", Stage: DownloadStage, Err: errors.New("404")},
+		{URL: "http://example.com/b", Stage: PipelineStage, Err: errors.New("disk full")},
+	}}
+
+	expected := "2 crawl errors : download of http://example.com/a failed : 404, pipeline of http://example.com/b failed : disk full"
+	if crawlErrors.Error() != expected {
+		t.Logf("%s different of %s", crawlErrors.Error(), expected)
+		t.Fail()
+	}
+
+	crawlErrors.Cause = ErrTooManyErrors
+	if !errors.Is(crawlErrors, ErrTooManyErrors) || crawlErrors.Error() != "crawl stopped : too many crawl errors, "+expected {
+		t.Logf("Wrong stopped crawl error : %s", crawlErrors.Error())
+		t.Fail()
+	}
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// sniffedEncodings are tried in order on content that declares no charset
+// and is not valid UTF-8.
+var sniffedEncodings = []struct {
+	name     string
+	encoding encoding.Encoding
+}{
+	{"shift_jis", japanese.ShiftJIS},
+	{"euc-jp", japanese.EUCJP},
+}
+
+// Text returns the content of the resource transcoded to UTF-8. The
+// original bytes stay available through RawContent.
+func (resource *WebResource) Text() string {
+	text, _ := resource.decode(resource.cache())
+
+	return text
+}
+
+// Charset returns the name of the character encoding the content was
+// decoded from, e.g. "utf-8", "windows-1252" or "shift_jis".
+//
+// It comes from, in order : a byte order mark, the charset parameter of the
+// Content-Type, a <meta charset> or <meta http-equiv="Content-Type">
+// declaration in the first 1024 bytes, and finally the content itself,
+// which is read as UTF-8 when valid, as Shift_JIS or EUC-JP when it decodes
+// to Japanese text and as windows-1252 otherwise.
+func (resource *WebResource) Charset() string {
+	_, name := resource.decode(resource.cache())
+
+	return name
+}
+
+// decode transcodes the content to UTF-8 a single time per cache.
+func (resource *WebResource) decode(dom *domCache) (string, string) {
+	content, contentType := resource.rawContent, resource.contentType
+
+	dom.textOnce.Do(func() {
+		dom.text, dom.charset = decodeContent(content, contentType)
+	})
+
+	return dom.text, dom.charset
+}
+
+func decodeContent(content []byte, contentType string) (string, string) {
+	encoding, name, certain := charset.DetermineEncoding(content, contentType)
+
+	// Without a declaration, only the first 1024 bytes were sniffed. Pages
+	// starting with plain ASCII may still be UTF-8 further down.
+	if !certain && name == "windows-1252" {
+		if utf8.Valid(content) {
+			return string(content), "utf-8"
+		}
+
+		for _, sniffed := range sniffedEncodings {
+			text, err := sniffed.encoding.NewDecoder().Bytes(content)
+			if err == nil && looksJapanese(string(text)) {
+				return string(text), sniffed.name
+			}
+		}
+	}
+
+	if name == "utf-8" {
+		// Invalid sequences are replaced with U+FFFD, like decoders do
+		content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+		return string(bytes.ToValidUTF8(content, []byte("\ufffd"))), name
+	}
+
+	text, err := encoding.NewDecoder().Bytes(content)
+	if err != nil {
+		return string(content), name
+	}
+
+	return strings.TrimPrefix(string(text), "\ufeff"), name
+}
+
+// looksJapanese tells whether text decoded without errors and a fair share
+// of its non-ASCII characters are kana, which Latin text decoded with a
+// Japanese encoding by mistake does not produce.
+func looksJapanese(text string) bool {
+	nonASCII, kana := 0, 0
+	for _, character := range text {
+		switch {
+		case character == utf8.RuneError:
+			return false
+		case character < utf8.RuneSelf:
+		case character >= 0x3040 && character <= 0x30ff:
+			nonASCII++
+			kana++
+		default:
+			nonASCII++
+		}
+	}
+
+	return kana > 0 && kana*5 >= nonASCII
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestCharsetDetection(t *testing.T) {
+	shiftJIS, _ := japanese.ShiftJIS.NewEncoder().String("<html><body><p>日本語のページ</p></body></html>")
+	eucJP, _ := japanese.EUCJP.NewEncoder().String("<html><body><p>日本語のページ</p></body></html>")
+
+	cases := []struct {
+		name        string
+		contentType string
+		content     string
+		charset     string
+		text        string
+	}{
+		{"content type", "text/html; charset=ISO-8859-1", "<p>caf\xe9</p>", "windows-1252", "<p>café</p>"},
+		{"content type over meta", "text/html; charset=utf-8", `<meta charset="iso-8859-1"><p>café</p>`, "utf-8", `<meta charset="iso-8859-1"><p>café</p>`},
+		{"meta charset", "text/html", "<meta charset=\"iso-8859-1\"><p>caf\xe9</p>", "windows-1252", `<meta charset="iso-8859-1"><p>café</p>`},
+		{"meta http-equiv", "text/html", shiftJISPage(t, `<meta http-equiv="Content-Type" content="text/html; charset=Shift_JIS">`), "shift_jis", `<meta http-equiv="Content-Type" content="text/html; charset=Shift_JIS"><p>日本語</p>`},
+		{"content type shift_jis", "text/html; charset=shift_jis", shiftJIS, "shift_jis", "<html><body><p>日本語のページ</p></body></html>"},
+		{"utf-8 bom", "text/html", "\xef\xbb\xbf<p>café</p>", "utf-8", "<p>café</p>"},
+		{"utf-16 bom", "text/html", "\xff\xfe<\x00p\x00>\x00\xe9\x00", "utf-16le", "<p>é"},
+		{"sniffed utf-8", "text/html", "<p>café</p>", "utf-8", "<p>café</p>"},
+		{"sniffed windows-1252", "text/html", "<p>caf\xe9</p>", "windows-1252", "<p>café</p>"},
+		{"sniffed windows-1252 valid as shift_jis", "text/html", "<p>\xe9t\xe9s</p>", "windows-1252", "<p>étés</p>"},
+		{"sniffed shift_jis", "text/html", shiftJIS, "shift_jis", "<html><body><p>日本語のページ</p></body></html>"},
+		{"sniffed euc-jp", "text/html", eucJP, "euc-jp", "<html><body><p>日本語のページ</p></body></html>"},
+	}
+
+	for _, c := range cases {
+		webResource, err := NewWebResource("https://example.com/", c.contentType, []byte(c.content))
+		if err != nil {
+			t.Log("Could not create WebResource")
+			t.FailNow()
+		}
+
+		if webResource.Charset() != c.charset {
+			t.Logf("%s : charset %s different of %s", c.name, webResource.Charset(), c.charset)
+			t.Fail()
+		}
+
+		if webResource.Text() != c.text {
+			t.Logf("%s : text %q different of %q", c.name, webResource.Text(), c.text)
+			t.Fail()
+		}
+
+		if string(webResource.RawContent()) != c.content {
+			t.Logf("%s : raw content should be left untouched", c.name)
+			t.Fail()
+		}
+	}
+}
+
+func TestUTF8AfterSniffedBytes(t *testing.T) {
+	content := make([]byte, 0)
+	for len(content) < 2000 {
+		content = append(content, "<!-- padding -->"...)
+	}
+	content = append(content, "<p>café</p>"...)
+
+	webResource, _ := NewWebResource("https://example.com/", "text/html", content)
+
+	if webResource.Charset() != "utf-8" {
+		t.Logf("UTF-8 past the first 1024 bytes should be detected, got %s", webResource.Charset())
+		t.Fail()
+	}
+}
+
+func TestSelectDecodedContent(t *testing.T) {
+	content := "<html><head><meta charset=\"iso-8859-1\"></head><body><h1>D\xe9j\xe0 vu</h1><a href=\"/caf\xe9\">x</a></body></html>"
+	webResource, _ := NewWebResource("https://example.com/", "text/html", []byte(content))
+
+	if title := webResource.SelectCSS("h1").Text(); title != "Déjà vu" {
+		t.Logf("Title %s different of Déjà vu", title)
+		t.Fail()
+	}
+
+	links, _ := webResource.LinksUrls()
+	expected := []string{"https://example.com/caf%C3%A9"}
+	if !linksMatch(expected, links) {
+		t.Logf("Extracted urls %s does not match expected ones %s", links, expected)
+		t.Fail()
+	}
+}
+
+func shiftJISPage(t *testing.T, head string) string {
+	body, err := japanese.ShiftJIS.NewEncoder().String("<p>日本語</p>")
+	if err != nil {
+		t.Log("Could not encode Shift_JIS")
+		t.FailNow()
+	}
+
+	return head + body
+}
@@ -0,0 +1,491 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CSSToXPath translates a CSS selector into an XPath expression selecting the
+// same elements among the descendants of the context node.
+// Supported are type, universal, id, class and attribute selectors, the
+// descendant, child, next-sibling and subsequent-sibling combinators,
+// selector lists and the :first-child, :last-child, :only-child,
+// :nth-child(an+b), :empty and :not() pseudo-classes.
+// A selector may start with a combinator, as in "> li", to select relative
+// to the context node.
+func CSSToXPath(selector string) (string, error) {
+	parser := &cssParser{input: strings.TrimSpace(selector)}
+
+	if parser.done() {
+		return "", fmt.Errorf("empty css selector")
+	}
+
+	paths := make([]string, 0)
+	for {
+		path, err := parser.parseComplex()
+		if err != nil {
+			return "", err
+		}
+		paths = append(paths, path)
+
+		parser.skipSpaces()
+		if parser.done() {
+			break
+		}
+
+		if parser.peek() != ',' {
+			return "", parser.errorf("unexpected %q", parser.peek())
+		}
+		parser.pos++
+	}
+
+	return strings.Join(paths, " | "), nil
+}
+
+type cssParser struct {
+	input string
+	pos   int
+}
+
+func (parser *cssParser) done() bool {
+	return parser.pos >= len(parser.input)
+}
+
+func (parser *cssParser) peek() byte {
+	return parser.input[parser.pos]
+}
+
+func (parser *cssParser) skipSpaces() bool {
+	start := parser.pos
+	for !parser.done() && unicode.IsSpace(rune(parser.peek())) {
+		parser.pos++
+	}
+
+	return parser.pos > start
+}
+
+func (parser *cssParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("css selector %q at %d : %s", parser.input, parser.pos, fmt.Sprintf(format, args...))
+}
+
+// parseComplex parses compound selectors separated by combinators.
+func (parser *cssParser) parseComplex() (string, error) {
+	parser.skipSpaces()
+
+	axis := "descendant::"
+	if !parser.done() {
+		switch parser.peek() {
+		case '>':
+			axis = "child::"
+		case '~':
+			axis = "following-sibling::"
+		case '+':
+			axis = "following-sibling::*[1]/self::"
+		}
+
+		if axis != "descendant::" {
+			parser.pos++
+			parser.skipSpaces()
+		}
+	}
+
+	step, err := parser.parseCompound()
+	if err != nil {
+		return "", err
+	}
+	path := axis + step
+
+	for {
+		hadSpaces := parser.skipSpaces()
+		if parser.done() || parser.peek() == ',' || parser.peek() == ')' {
+			return path, nil
+		}
+
+		combinator := byte(' ')
+		if next := parser.peek(); next == '>' || next == '+' || next == '~' {
+			combinator = next
+			parser.pos++
+			parser.skipSpaces()
+		} else if !hadSpaces {
+			return "", parser.errorf("unexpected %q", next)
+		}
+
+		step, err = parser.parseCompound()
+		if err != nil {
+			return "", err
+		}
+
+		switch combinator {
+		case ' ':
+			path += "/descendant::" + step
+		case '>':
+			path += "/" + step
+		case '~':
+			path += "/following-sibling::" + step
+		case '+':
+			path += "/following-sibling::*[1]/self::" + step
+		}
+	}
+}
+
+// parseCompound parses a type selector followed by any number of id, class,
+// attribute and pseudo-class selectors into an XPath step.
+func (parser *cssParser) parseCompound() (string, error) {
+	if parser.done() {
+		return "", parser.errorf("missing selector")
+	}
+
+	name := "*"
+	universal := false
+	if parser.peek() == '*' {
+		parser.pos++
+		universal = true
+	} else if identifier := parser.parseIdentifier(); identifier != "" {
+		name = strings.ToLower(identifier)
+	}
+
+	predicates, err := parser.parsePredicates()
+	if err != nil {
+		return "", err
+	}
+
+	if name == "*" && len(predicates) == 0 && !universal {
+		return "", parser.errorf("missing selector")
+	}
+
+	step := name
+	for _, predicate := range predicates {
+		step += "[" + predicate + "]"
+	}
+
+	return step, nil
+}
+
+func (parser *cssParser) parsePredicates() ([]string, error) {
+	predicates := make([]string, 0)
+
+	for !parser.done() {
+		switch parser.peek() {
+		case '#':
+			parser.pos++
+			id := parser.parseIdentifier()
+			if id == "" {
+				return nil, parser.errorf("missing id")
+			}
+			predicates = append(predicates, "@id="+xpathLiteral(id))
+		case '.':
+			parser.pos++
+			class := parser.parseIdentifier()
+			if class == "" {
+				return nil, parser.errorf("missing class")
+			}
+			predicates = append(predicates, containsWord("@class", class))
+		case '[':
+			predicate, err := parser.parseAttribute()
+			if err != nil {
+				return nil, err
+			}
+			predicates = append(predicates, predicate)
+		case ':':
+			predicate, err := parser.parsePseudoClass()
+			if err != nil {
+				return nil, err
+			}
+			predicates = append(predicates, predicate)
+		default:
+			return predicates, nil
+		}
+	}
+
+	return predicates, nil
+}
+
+func (parser *cssParser) parseAttribute() (string, error) {
+	parser.pos++ // [
+	parser.skipSpaces()
+
+	name := strings.ToLower(parser.parseIdentifier())
+	if name == "" {
+		return "", parser.errorf("missing attribute name")
+	}
+	attribute := "@" + name
+
+	parser.skipSpaces()
+	if parser.done() {
+		return "", parser.errorf("unterminated attribute selector")
+	}
+
+	if parser.peek() == ']' {
+		parser.pos++
+		return attribute, nil
+	}
+
+	operator := ""
+	if strings.ContainsRune("~^$*|", rune(parser.peek())) {
+		operator = string(parser.peek())
+		parser.pos++
+	}
+	if parser.done() || parser.peek() != '=' {
+		return "", parser.errorf("missing attribute operator")
+	}
+	parser.pos++
+	parser.skipSpaces()
+
+	value, err := parser.parseValue()
+	if err != nil {
+		return "", err
+	}
+
+	parser.skipSpaces()
+	if parser.done() || parser.peek() != ']' {
+		return "", parser.errorf("unterminated attribute selector")
+	}
+	parser.pos++
+
+	literal := xpathLiteral(value)
+	switch operator {
+	case "~":
+		return containsWord(attribute, value), nil
+	case "^":
+		return fmt.Sprintf("starts-with(%s, %s)", attribute, literal), nil
+	case "$":
+		return fmt.Sprintf("ends-with(%s, %s)", attribute, literal), nil
+	case "*":
+		return fmt.Sprintf("contains(%s, %s)", attribute, literal), nil
+	case "|":
+		return fmt.Sprintf("(%s=%s or starts-with(%s, %s))", attribute, literal, attribute, xpathLiteral(value+"-")), nil
+	}
+
+	return attribute + "=" + literal, nil
+}
+
+func (parser *cssParser) parsePseudoClass() (string, error) {
+	parser.pos++ // :
+	name := strings.ToLower(parser.parseIdentifier())
+
+	switch name {
+	case "first-child":
+		return "not(preceding-sibling::*)", nil
+	case "last-child":
+		return "not(following-sibling::*)", nil
+	case "only-child":
+		return "not(preceding-sibling::*) and not(following-sibling::*)", nil
+	case "empty":
+		return "not(*) and not(text())", nil
+	case "nth-child":
+		argument, err := parser.parseArgument()
+		if err != nil {
+			return "", err
+		}
+
+		a, b, ok := parseNth(argument)
+		if !ok {
+			return "", parser.errorf("unsupported :nth-child argument %q", argument)
+		}
+		return nthChildPredicate(a, b), nil
+	case "not":
+		argument, err := parser.parseArgument()
+		if err != nil {
+			return "", err
+		}
+
+		negated := &cssParser{input: argument}
+		step, err := negated.parseCompound()
+		if err != nil {
+			return "", err
+		}
+		if !negated.done() {
+			return "", parser.errorf(":not only supports compound selectors")
+		}
+		return "not(self::" + step + ")", nil
+	}
+
+	return "", parser.errorf("unsupported pseudo-class :%s", name)
+}
+
+// parseNth parses the an+b argument of :nth-child, odd and even included.
+func parseNth(argument string) (int, int, bool) {
+	argument = strings.ToLower(strings.Join(strings.Fields(argument), ""))
+
+	switch argument {
+	case "odd":
+		return 2, 1, true
+	case "even":
+		return 2, 0, true
+	}
+
+	index := strings.IndexByte(argument, 'n')
+	if index < 0 {
+		b, err := strconv.Atoi(argument)
+		return 0, b, err == nil
+	}
+
+	a := 1
+	switch coefficient := argument[:index]; coefficient {
+	case "", "+":
+	case "-":
+		a = -1
+	default:
+		parsed, err := strconv.Atoi(coefficient)
+		if err != nil {
+			return 0, 0, false
+		}
+		a = parsed
+	}
+
+	b := 0
+	if offset := argument[index+1:]; offset != "" {
+		if offset[0] != '+' && offset[0] != '-' {
+			return 0, 0, false
+		}
+		parsed, err := strconv.Atoi(offset)
+		if err != nil {
+			return 0, 0, false
+		}
+		b = parsed
+	}
+
+	return a, b, true
+}
+
+// nthChildPredicate matches the elements whose position p among their
+// siblings is a*n+b for some n >= 0.
+func nthChildPredicate(a int, b int) string {
+	count := "count(preceding-sibling::*)"
+	// Positions start at 1, count starts at 0
+	offset := b - 1
+
+	if a == 0 {
+		if offset < 0 {
+			return "false()"
+		}
+		return fmt.Sprintf("%s = %d", count, offset)
+	}
+
+	conditions := make([]string, 0)
+	if a > 0 {
+		if offset > 0 {
+			conditions = append(conditions, fmt.Sprintf("%s >= %d", count, offset))
+		}
+
+		if a != 1 {
+			term := count
+			if offset > 0 {
+				term = fmt.Sprintf("(%s - %d)", count, offset)
+			} else if offset < 0 {
+				term = fmt.Sprintf("(%s + %d)", count, -offset)
+			}
+			conditions = append(conditions, fmt.Sprintf("%s mod %d = 0", term, a))
+		}
+	} else {
+		if offset < 0 {
+			return "false()"
+		}
+		conditions = append(conditions, fmt.Sprintf("%s <= %d", count, offset))
+
+		if a != -1 {
+			conditions = append(conditions, fmt.Sprintf("(%d - %s) mod %d = 0", offset, count, -a))
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "true()"
+	}
+
+	return strings.Join(conditions, " and ")
+}
+
+// parseArgument returns the trimmed text between the parentheses following
+// a functional pseudo-class, nested parentheses and quoted strings included.
+func (parser *cssParser) parseArgument() (string, error) {
+	if parser.done() || parser.peek() != '(' {
+		return "", parser.errorf("missing argument")
+	}
+
+	depth := 0
+	var quote byte
+	for end := parser.pos; end < len(parser.input); end++ {
+		character := parser.input[end]
+
+		switch {
+		case quote != 0:
+			if character == quote {
+				quote = 0
+			}
+		case character == '"' || character == '\'':
+			quote = character
+		case character == '(':
+			depth++
+		case character == ')':
+			depth--
+			if depth == 0 {
+				argument := parser.input[parser.pos+1 : end]
+				parser.pos = end + 1
+				return strings.TrimSpace(argument), nil
+			}
+		}
+	}
+
+	return "", parser.errorf("unterminated argument")
+}
+
+func (parser *cssParser) parseValue() (string, error) {
+	if parser.done() {
+		return "", parser.errorf("missing attribute value")
+	}
+
+	quote := parser.peek()
+	if quote != '"' && quote != '\'' {
+		value := parser.parseIdentifier()
+		if value == "" {
+			return "", parser.errorf("missing attribute value")
+		}
+		return value, nil
+	}
+
+	end := strings.IndexByte(parser.input[parser.pos+1:], quote)
+	if end < 0 {
+		return "", parser.errorf("unterminated string")
+	}
+
+	value := parser.input[parser.pos+1 : parser.pos+1+end]
+	parser.pos += end + 2
+
+	return value, nil
+}
+
+func (parser *cssParser) parseIdentifier() string {
+	start := parser.pos
+	for !parser.done() {
+		character := rune(parser.peek())
+		if character == '-' || character == '_' || character >= 0x80 || unicode.IsLetter(character) || unicode.IsDigit(character) {
+			parser.pos++
+		} else {
+			break
+		}
+	}
+
+	return parser.input[start:parser.pos]
+}
+
+// containsWord is the XPath version of the CSS ~= operator, matching one of
+// the whitespace separated words of an attribute.
+func containsWord(attribute string, word string) string {
+	return fmt.Sprintf("contains(concat(' ', normalize-space(%s), ' '), %s)", attribute, xpathLiteral(" "+word+" "))
+}
+
+// xpathLiteral quotes value as an XPath string literal.
+func xpathLiteral(value string) string {
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+
+	if !strings.Contains(value, `"`) {
+		return `"` + value + `"`
+	}
+
+	parts := strings.Split(value, "'")
+	return "concat('" + strings.Join(parts, `', "'", '`) + "')"
+}
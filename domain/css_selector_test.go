@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestCSSToXPath(t *testing.T) {
+	cases := []struct {
+		selector string
+		expected string
+	}{
+		{"div", "descendant::div"},
+		{"*", "descendant::*"},
+		{"DIV", "descendant::div"},
+		{"#main", "descendant::*[@id='main']"},
+		{"p.intro", "descendant::p[contains(concat(' ', normalize-space(@class), ' '), ' intro ')]"},
+		{"a[href]", "descendant::a[@href]"},
+		{"a[href='/x']", "descendant::a[@href='/x']"},
+		{`a[title="it's"]`, `descendant::a[@title="it's"]`},
+		{"a[href^=http]", "descendant::a[starts-with(@href, 'http')]"},
+		{"a[href$='.pdf']", "descendant::a[ends-with(@href, '.pdf')]"},
+		{"a[href*=example]", "descendant::a[contains(@href, 'example')]"},
+		{"a[rel~=nofollow]", "descendant::a[contains(concat(' ', normalize-space(@rel), ' '), ' nofollow ')]"},
+		{"html[lang|=en]", "descendant::html[(@lang='en' or starts-with(@lang, 'en-'))]"},
+		{"ul li", "descendant::ul/descendant::li"},
+		{"ul > li", "descendant::ul/li"},
+		{"ul>li", "descendant::ul/li"},
+		{"h1 ~ p", "descendant::h1/following-sibling::p"},
+		{"h1 + p", "descendant::h1/following-sibling::*[1]/self::p"},
+		{"h1, h2", "descendant::h1 | descendant::h2"},
+		{"li:first-child", "descendant::li[not(preceding-sibling::*)]"},
+		{"li:last-child", "descendant::li[not(following-sibling::*)]"},
+		{"li:nth-child(2)", "descendant::li[count(preceding-sibling::*) = 1]"},
+		{"li:nth-child(odd)", "descendant::li[count(preceding-sibling::*) mod 2 = 0]"},
+		{"li:nth-child(even)", "descendant::li[(count(preceding-sibling::*) + 1) mod 2 = 0]"},
+		{"li:nth-child(3n+2)", "descendant::li[count(preceding-sibling::*) >= 1 and (count(preceding-sibling::*) - 1) mod 3 = 0]"},
+		{"li:nth-child(-n + 3)", "descendant::li[count(preceding-sibling::*) <= 2]"},
+		{"li:nth-child(n)", "descendant::li[true()]"},
+		{"li:nth-child(0)", "descendant::li[false()]"},
+		{"> li", "child::li"},
+		{"+ p", "following-sibling::*[1]/self::p"},
+		{"~ p.note", "following-sibling::p[contains(concat(' ', normalize-space(@class), ' '), ' note ')]"},
+		{"p:empty", "descendant::p[not(*) and not(text())]"},
+		{"li:not(.active)", "descendant::li[not(self::*[contains(concat(' ', normalize-space(@class), ' '), ' active ')])]"},
+		{"li:not(:nth-child(2))", "descendant::li[not(self::*[count(preceding-sibling::*) = 1])]"},
+		{"a:not([title='a)b'])", "descendant::a[not(self::*[@title='a)b'])]"},
+	}
+
+	for _, c := range cases {
+		expr, err := CSSToXPath(c.selector)
+
+		if err != nil {
+			t.Logf("Could not translate %q : %s", c.selector, err)
+			t.Fail()
+			continue
+		}
+
+		if expr != c.expected {
+			t.Logf("%q : %s different of %s", c.selector, expr, c.expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestCSSToXPathInvalid(t *testing.T) {
+	selectors := []string{"", "div,", "a[href", "a[href=]", "li:hover", "li:nth-child(2n1)", "li:nth-child(n+)", "li:nth-child(x)", "p > ", "#", "a[href='x]",
+		">", ">p >", ",a", "a,,b", "p:not(>a)", "p:not(+a)", "> > p", "li:not(:nth-child(2)", "li:not(a))"}
+
+	for _, selector := range selectors {
+		if expr, err := CSSToXPath(selector); err == nil {
+			t.Logf("%q should not be translated, got %s", selector, expr)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package domain
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/antchfx/htmlquery"
+)
+
+// ImageCandidate is an image url of a srcset attribute along with its
+// descriptor. Width is set for width descriptors such as "640w", Density
+// otherwise, defaulting to 1 when the candidate has no descriptor.
+type ImageCandidate struct {
+	URL     string
+	Width   int
+	Density float64
+}
+
+// LazyImagesUrls returns the data-src urls of lazy-loaded images.
+func (resource *WebResource) LazyImagesUrls() ([]string, error) {
+	return resource.extractUrls("//img/@data-src", "data-src")
+}
+
+// ImageCandidates returns the candidates of the srcset and data-srcset
+// attributes of images and of the sources of <picture> elements.
+func (resource *WebResource) ImageCandidates() ([]ImageCandidate, error) {
+	candidates := make([]ImageCandidate, 0)
+	doc, err := resource.document()
+
+	if err != nil {
+		return candidates, err
+	}
+
+	base := resource.baseUrl(doc)
+	for _, n := range htmlquery.Find(doc, "//img[@srcset or @data-srcset] | //picture/source[@srcset]") {
+		srcset := htmlquery.SelectAttr(n, "srcset")
+		if srcset == "" {
+			srcset = htmlquery.SelectAttr(n, "data-srcset")
+		}
+
+		for _, candidate := range ParseSrcset(srcset) {
+			resolvedUrl, err := resource.resolveUrl(candidate.URL, base)
+			if err != nil {
+				log.Printf("Could not parse url %s\n", candidate.URL)
+				continue
+			}
+
+			candidate.URL = resolvedUrl
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	return candidates, nil
+}
+
+// VideosUrls returns the urls of <video> elements and of their sources.
+func (resource *WebResource) VideosUrls() ([]string, error) {
+	return resource.extractUrls("//video/@src | //video/source/@src", "src")
+}
+
+// VideoPostersUrls returns the urls of the images shown before videos play.
+func (resource *WebResource) VideoPostersUrls() ([]string, error) {
+	return resource.extractUrls("//video/@poster", "poster")
+}
+
+// AudiosUrls returns the urls of <audio> elements and of their sources.
+func (resource *WebResource) AudiosUrls() ([]string, error) {
+	return resource.extractUrls("//audio/@src | //audio/source/@src", "src")
+}
+
+// TracksUrls returns the urls of the text tracks, such as subtitles, of
+// videos and audios.
+func (resource *WebResource) TracksUrls() ([]string, error) {
+	return resource.extractUrls("//track/@src", "src")
+}
+
+func (resource *WebResource) IframesUrls() ([]string, error) {
+	return resource.extractUrls("//iframe/@src", "src")
+}
+
+func (resource *WebResource) ObjectsUrls() ([]string, error) {
+	return resource.extractUrls("//object/@data", "data")
+}
+
+// IconsUrls returns the urls of the favicons and touch icons of the page.
+func (resource *WebResource) IconsUrls() ([]string, error) {
+	rel := "concat(' ', normalize-space(translate(@rel, 'ABCDEFGHIJKLMNOPQRSTUVWXYZ', 'abcdefghijklmnopqrstuvwxyz')), ' ')"
+	expr := "//link[contains(" + rel + ", ' icon ') or contains(" + rel + ", ' apple-touch-icon ') or contains(" + rel + ", ' apple-touch-icon-precomposed ') or contains(" + rel + ", ' mask-icon ')]/@href"
+
+	return resource.extractUrls(expr, "href")
+}
+
+// ParseSrcset returns the candidates of a srcset attribute, following the
+// parsing rules of the HTML standard. Urls are returned as written and
+// candidates with invalid descriptors are dropped.
+func ParseSrcset(srcset string) []ImageCandidate {
+	candidates := make([]ImageCandidate, 0)
+	position := 0
+
+	for {
+		// Skip the separators before the url
+		for position < len(srcset) && (isSrcsetSpace(srcset[position]) || srcset[position] == ',') {
+			position++
+		}
+		if position >= len(srcset) {
+			return candidates
+		}
+
+		start := position
+		for position < len(srcset) && !isSrcsetSpace(srcset[position]) {
+			position++
+		}
+		candidateUrl := srcset[start:position]
+
+		descriptors := make([]string, 0)
+		if strings.HasSuffix(candidateUrl, ",") {
+			candidateUrl = strings.TrimRight(candidateUrl, ",")
+		} else {
+			descriptors, position = parseSrcsetDescriptors(srcset, position)
+		}
+
+		if candidate, ok := newImageCandidate(candidateUrl, descriptors); ok {
+			candidates = append(candidates, candidate)
+		}
+	}
+}
+
+// parseSrcsetDescriptors reads the descriptors following a candidate url, up
+// to the comma ending the candidate. Commas between parentheses do not end
+// the candidate.
+func parseSrcsetDescriptors(srcset string, position int) ([]string, int) {
+	descriptors := make([]string, 0)
+	var descriptor strings.Builder
+	inParentheses := false
+
+	flush := func() {
+		if descriptor.Len() > 0 {
+			descriptors = append(descriptors, descriptor.String())
+			descriptor.Reset()
+		}
+	}
+
+	for ; position < len(srcset); position++ {
+		character := srcset[position]
+
+		switch {
+		case inParentheses:
+			descriptor.WriteByte(character)
+			inParentheses = character != ')'
+		case character == ',':
+			flush()
+			return descriptors, position + 1
+		case isSrcsetSpace(character):
+			flush()
+		default:
+			descriptor.WriteByte(character)
+			inParentheses = character == '('
+		}
+	}
+
+	flush()
+	return descriptors, position
+}
+
+func newImageCandidate(candidateUrl string, descriptors []string) (ImageCandidate, bool) {
+	candidate := ImageCandidate{URL: candidateUrl}
+	hasHeight := false
+
+	for _, descriptor := range descriptors {
+		value := descriptor[:len(descriptor)-1]
+
+		switch unicode.ToLower(rune(descriptor[len(descriptor)-1])) {
+		case 'w':
+			width, err := strconv.Atoi(value)
+			if err != nil || width <= 0 || candidate.Width != 0 || candidate.Density != 0 {
+				return candidate, false
+			}
+			candidate.Width = width
+		case 'x':
+			density, err := strconv.ParseFloat(value, 64)
+			if err != nil || density <= 0 || candidate.Width != 0 || candidate.Density != 0 {
+				return candidate, false
+			}
+			candidate.Density = density
+		case 'h':
+			// Height descriptors are only valid along a width one
+			hasHeight = true
+		default:
+			return candidate, false
+		}
+	}
+
+	if hasHeight && candidate.Width == 0 {
+		return candidate, false
+	}
+
+	if candidate.Width == 0 && candidate.Density == 0 {
+		candidate.Density = 1
+	}
+
+	return candidate, true
+}
+
+func isSrcsetSpace(character byte) bool {
+	switch character {
+	case ' ', '\t', '\n', '\f', '\r':
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestParseSrcset(t *testing.T) {
+	cases := []struct {
+		srcset   string
+		expected []ImageCandidate
+	}{
+		{"image.jpg", []ImageCandidate{{"image.jpg", 0, 1}}},
+		{"small.jpg 480w, large.jpg 1080w", []ImageCandidate{{"small.jpg", 480, 0}, {"large.jpg", 1080, 0}}},
+		{"a.png 1x,b.png 2x , c.png 1.5x", []ImageCandidate{{"a.png", 0, 1}, {"b.png", 0, 2}, {"c.png", 0, 1.5}}},
+		{"  \n a.png,  b.png 2x", []ImageCandidate{{"a.png", 0, 1}, {"b.png", 0, 2}}},
+		{"https://cdn.example.com/w_100,h_100/a.jpg 100w", []ImageCandidate{{"https://cdn.example.com/w_100,h_100/a.jpg", 100, 0}}},
+		{"a.png 100w 200h, b.png 200h", []ImageCandidate{{"a.png", 100, 0}}},
+		{"a.png 2y, b.png 100w 2x, c.png -1w, d.png 3x", []ImageCandidate{{"d.png", 0, 3}}},
+		{"a.png 0x, b.png 2x, c.png -1x", []ImageCandidate{{"b.png", 0, 2}}},
+		{"", []ImageCandidate{}},
+	}
+
+	for _, c := range cases {
+		candidates := ParseSrcset(c.srcset)
+
+		if !candidatesMatch(c.expected, candidates) {
+			t.Logf("%q : candidates %v different of %v", c.srcset, candidates, c.expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestEmbeddedResources(t *testing.T) {
+	strContent := `
+	<html>
+		<head>
+			<link rel="icon" href="/favicon.ico">
+			<link rel="Shortcut Icon" href="/shortcut.ico">
+			<link rel="apple-touch-icon" href="/touch.png">
+			<link rel="stylesheet" href="/style.css">
+		</head>
+		<body>
+			<img src="plain.jpg" srcset="plain-2x.jpg 2x">
+			<img data-src="/lazy.jpg" data-srcset="/lazy-640.jpg 640w">
+			<picture>
+				<source srcset="photo.webp 800w, photo-large.webp 1600w" type="image/webp">
+				<img src="photo.jpg">
+			</picture>
+			<video src="/movie.mp4" poster="/poster.jpg">
+				<source src="/movie.webm" type="video/webm">
+				<track src="/subtitles.vtt" kind="subtitles">
+			</video>
+			<audio><source src="/song.ogg"></audio>
+			<iframe src="https://www.youtube.com/embed/x"></iframe>
+			<object data="/document.pdf"></object>
+		</body>
+	</html>
+	`
+	webResource, err := NewWebResource("https://example.com/gallery/", "text/html", []byte(strContent))
+
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+
+	candidates, err := webResource.ImageCandidates()
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	expectedCandidates := []ImageCandidate{
+		{"https://example.com/gallery/plain-2x.jpg", 0, 2},
+		{"https://example.com/lazy-640.jpg", 640, 0},
+		{"https://example.com/gallery/photo.webp", 800, 0},
+		{"https://example.com/gallery/photo-large.webp", 1600, 0},
+	}
+	if !candidatesMatch(expectedCandidates, candidates) {
+		t.Logf("Candidates %v different of %v", candidates, expectedCandidates)
+		t.Fail()
+	}
+
+	extractors := []struct {
+		name      string
+		extractor func() ([]string, error)
+		expected  []string
+	}{
+		{"lazy images", webResource.LazyImagesUrls, []string{"https://example.com/lazy.jpg"}},
+		{"videos", webResource.VideosUrls, []string{"https://example.com/movie.mp4", "https://example.com/movie.webm"}},
+		{"posters", webResource.VideoPostersUrls, []string{"https://example.com/poster.jpg"}},
+		{"audios", webResource.AudiosUrls, []string{"https://example.com/song.ogg"}},
+		{"tracks", webResource.TracksUrls, []string{"https://example.com/subtitles.vtt"}},
+		{"iframes", webResource.IframesUrls, []string{"https://www.youtube.com/embed/x"}},
+		{"objects", webResource.ObjectsUrls, []string{"https://example.com/document.pdf"}},
+		{"icons", webResource.IconsUrls, []string{"https://example.com/favicon.ico", "https://example.com/shortcut.ico", "https://example.com/touch.png"}},
+	}
+
+	for _, e := range extractors {
+		urls, err := e.extractor()
+
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+
+		if !linksMatch(e.expected, urls) {
+			t.Logf("%s : extracted urls %s does not match expected ones %s", e.name, urls, e.expected)
+			t.Fail()
+		}
+	}
+}
+
+func candidatesMatch(expected []ImageCandidate, received []ImageCandidate) bool {
+	if len(expected) != len(received) {
+		return false
+	}
+
+	for index, expect := range expected {
+		if expect != received[index] {
+			return false
+		}
+	}
+	return true
+}
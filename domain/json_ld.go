@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+)
+
+// JSONLD returns the JSON-LD objects of the <script type="application/ld+json">
+// blocks of the page. Arrays and @graph lists are flattened into their
+// objects. Blocks that are not valid JSON are skipped.
+func (resource *WebResource) JSONLD() ([]map[string]interface{}, error) {
+	objects := make([]map[string]interface{}, 0)
+	doc, err := resource.document()
+
+	if err != nil {
+		return objects, err
+	}
+
+	for _, n := range htmlquery.Find(doc, "//script[@type]") {
+		mediaType := strings.ToLower(strings.TrimSpace(htmlquery.SelectAttr(n, "type")))
+		if mediaType != "application/ld+json" {
+			continue
+		}
+
+		var block interface{}
+		if err := json.Unmarshal([]byte(htmlquery.InnerText(n)), &block); err != nil {
+			log.Printf("Could not parse JSON-LD of %s : %s\n", resource.url, err)
+			continue
+		}
+
+		objects = append(objects, flattenJSONLD(block)...)
+	}
+
+	return objects, nil
+}
+
+func flattenJSONLD(block interface{}) []map[string]interface{} {
+	objects := make([]map[string]interface{}, 0)
+
+	switch value := block.(type) {
+	case []interface{}:
+		for _, item := range value {
+			objects = append(objects, flattenJSONLD(item)...)
+		}
+	case map[string]interface{}:
+		graph, ok := value["@graph"].([]interface{})
+		if !ok {
+			return append(objects, value)
+		}
+
+		for _, item := range graph {
+			for _, object := range flattenJSONLD(item) {
+				// Objects of a graph share the context of the graph
+				if _, ok := object["@context"]; !ok && value["@context"] != nil {
+					object["@context"] = value["@context"]
+				}
+				objects = append(objects, object)
+			}
+		}
+	}
+
+	return objects
+}
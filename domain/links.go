@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// Link is a hyperlink of a web page, from an <a> or <area> element with an
+// href attribute.
+type Link struct {
+	// URL is the absolute url the link points to.
+	URL string
+	// Href is the href attribute as written in the page.
+	Href string
+	// Text is the anchor text with spaces collapsed. For links wrapping
+	// images only, it is the alt text of the images.
+	Text string
+	// Rel holds the lowercased values of the rel attribute, e.g. "nofollow".
+	Rel      []string
+	Title    string
+	HrefLang string
+	Target   string
+	// Download is true when the link has a download attribute.
+	Download bool
+	// Attributes holds every attribute of the element, href included.
+	Attributes map[string]string
+	// XPath locates the element in the page, e.g. /html/body/div[2]/a.
+	XPath string
+	// Internal is true when the link points to the host of the page.
+	Internal bool
+}
+
+// HasRel reports whether value is one of the rel values of the link.
+func (link Link) HasRel(value string) bool {
+	value = strings.ToLower(value)
+	for _, rel := range link.Rel {
+		if rel == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NoFollow reports whether the link asks crawlers not to follow it, with
+// rel="nofollow", "ugc" or "sponsored".
+func (link Link) NoFollow() bool {
+	return link.HasRel("nofollow") || link.HasRel("ugc") || link.HasRel("sponsored")
+}
+
+// Links returns the links of the page in document order. Links whose href
+// cannot be parsed are skipped.
+func (resource *WebResource) Links() ([]Link, error) {
+	links := make([]Link, 0)
+	doc, err := resource.document()
+
+	if err != nil {
+		return links, err
+	}
+
+	base := resource.baseUrl(doc)
+	for _, n := range htmlquery.Find(doc, "//*[(self::a or self::area) and @href]") {
+		href := htmlquery.SelectAttr(n, "href")
+		resolvedUrl, err := resource.resolveUrl(href, base)
+		if err != nil {
+			log.Printf("Could not parse url %s\n", href)
+			continue
+		}
+
+		internal, _ := isInternalUrl(resolvedUrl, resource.url)
+		link := Link{
+			URL:        resolvedUrl,
+			Href:       href,
+			Text:       linkText(n),
+			Rel:        strings.Fields(strings.ToLower(htmlquery.SelectAttr(n, "rel"))),
+			Attributes: make(map[string]string, len(n.Attr)),
+			XPath:      nodeXPath(n),
+			Internal:   internal,
+		}
+
+		for _, attribute := range n.Attr {
+			link.Attributes[attribute.Key] = attribute.Val
+		}
+		link.Title = link.Attributes["title"]
+		link.HrefLang = link.Attributes["hreflang"]
+		link.Target = link.Attributes["target"]
+		_, link.Download = link.Attributes["download"]
+
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+func linkText(n *html.Node) string {
+	if n.Data == "area" {
+		return strings.TrimSpace(htmlquery.SelectAttr(n, "alt"))
+	}
+
+	text := strings.Join(strings.Fields(htmlquery.InnerText(n)), " ")
+	if text != "" {
+		return text
+	}
+
+	alts := make([]string, 0)
+	for _, image := range htmlquery.Find(n, ".//img[@alt]") {
+		if alt := strings.TrimSpace(htmlquery.SelectAttr(image, "alt")); alt != "" {
+			alts = append(alts, alt)
+		}
+	}
+
+	return strings.Join(alts, " ")
+}
+
+// nodeXPath returns an absolute XPath locating the element n. Positions are
+// only given for elements having siblings of the same name.
+func nodeXPath(n *html.Node) string {
+	steps := make([]string, 0)
+
+	for ; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		position, count := 1, 1
+		for sibling := n.PrevSibling; sibling != nil; sibling = sibling.PrevSibling {
+			if sibling.Type == html.ElementNode && sibling.Data == n.Data {
+				position++
+				count++
+			}
+		}
+		for sibling := n.NextSibling; sibling != nil; sibling = sibling.NextSibling {
+			if sibling.Type == html.ElementNode && sibling.Data == n.Data {
+				count++
+			}
+		}
+
+		step := n.Data
+		if count > 1 {
+			step = fmt.Sprintf("%s[%d]", n.Data, position)
+		}
+		steps = append([]string{step}, steps...)
+	}
+
+	return "/" + strings.Join(steps, "/")
+}
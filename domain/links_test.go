@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+func TestLinks(t *testing.T) {
+	strContent := `
+	<html>
+		<body>
+			<div>menu</div>
+			<div>
+				<a href="/about" title="About us" rel="Author NoFollow">  About
+					<b>the team</b>
+				</a>
+				<a href="https://other.com/fr/" hreflang="fr" target="_blank" rel="ugc">Version française</a>
+				<map><area href="/zone" alt="A zone"></map>
+				<a href="/report.pdf" download><img src="/pdf.png" alt="Annual report"></a>
+				<a name="anchor">No href</a>
+			</div>
+			<a href="/contact">Contact</a>
+		</body>
+	</html>
+	`
+	webResource, err := NewWebResource("https://example.com/company/", "text/html", []byte(strContent))
+
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+
+	links, err := webResource.Links()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if len(links) != 5 {
+		t.Logf("Expected 5 links, got %d", len(links))
+		t.FailNow()
+	}
+
+	about := links[0]
+	if about.URL != "https://example.com/about" || about.Href != "/about" {
+		t.Logf("Wrong urls %s, %s", about.URL, about.Href)
+		t.Fail()
+	}
+	if about.Text != "About the team" || about.Title != "About us" {
+		t.Logf("Wrong text %q or title %q", about.Text, about.Title)
+		t.Fail()
+	}
+	if !about.HasRel("author") || !about.NoFollow() || !about.Internal {
+		t.Logf("Wrong rel %s or internal %t", about.Rel, about.Internal)
+		t.Fail()
+	}
+	if about.XPath != "/html/body/div[2]/a[1]" {
+		t.Logf("Wrong xpath %s", about.XPath)
+		t.Fail()
+	}
+	if node := htmlquery.FindOne(mustDocument(t, webResource), about.XPath); htmlquery.SelectAttr(node, "href") != "/about" {
+		t.Log("XPath should locate the link")
+		t.Fail()
+	}
+
+	french := links[1]
+	if french.Internal || french.HrefLang != "fr" || french.Target != "_blank" || !french.NoFollow() {
+		t.Logf("Wrong attributes %+v", french)
+		t.Fail()
+	}
+
+	area := links[2]
+	if area.URL != "https://example.com/zone" || area.Text != "A zone" || area.XPath != "/html/body/div[2]/map/area" {
+		t.Logf("Wrong area link %+v", area)
+		t.Fail()
+	}
+
+	report := links[3]
+	if !report.Download || report.Text != "Annual report" || report.NoFollow() {
+		t.Logf("Wrong download link %+v", report)
+		t.Fail()
+	}
+	if _, ok := report.Attributes["download"]; !ok {
+		t.Log("Attributes should hold every attribute")
+		t.Fail()
+	}
+
+	if contact := links[4]; contact.URL != "https://example.com/contact" || contact.XPath != "/html/body/a" {
+		t.Logf("Links should be in document order, got %+v", contact)
+		t.Fail()
+	}
+}
+
+func mustDocument(t *testing.T, webResource *WebResource) *html.Node {
+	doc, err := webResource.document()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	return doc
+}
@@ -0,0 +1,488 @@
+package domain
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// MainContent is the main content of a page once navigation, footers,
+// scripts, styles and ads are stripped.
+type MainContent struct {
+	Title  string
+	Byline string
+	// PublishedAt is zero when no publish date could be found.
+	PublishedAt time.Time
+	// Text is the content as plain text, paragraphs separated by blank lines.
+	Text string
+	// HTML is the content as HTML restricted to headings, paragraphs,
+	// lists, quotes, code, tables, links and images, with absolute urls.
+	HTML string
+}
+
+var (
+	unlikelyCandidates = regexp.MustCompile(`(^|[\s_-])(ads?|advert\w*|banner|breadcrumbs?|comments?|cookies?|footer|menu|nav\w*|newsletter|popup|promo\w*|related|share|sidebar|social|sponsor\w*|subscribe)([\s_-]|$)`)
+	likelyCandidates   = regexp.MustCompile(`article|body|content|entry|main|post|story|text|blog`)
+	bylineCandidates   = regexp.MustCompile(`byline|author`)
+	spaces             = regexp.MustCompile(`\s+`)
+	blankLines         = regexp.MustCompile(`\n{3,}`)
+	titleSeparators    = regexp.MustCompile(` [|\-–—/»:] `)
+)
+
+// MainText finds the main content of the page with a readability-style
+// algorithm : paragraphs are scored by their length and commas, scores are
+// given to their ancestors and the best scored element, along with its
+// related siblings, is kept.
+func (resource *WebResource) MainText() (MainContent, error) {
+	doc, err := resource.document()
+
+	if err != nil {
+		return MainContent{}, err
+	}
+
+	extractor := readability{resource: resource, base: resource.baseUrl(doc), scores: make(map[*html.Node]float64)}
+	nodes := extractor.mainNodes(doc)
+
+	var text, content strings.Builder
+	for _, n := range nodes {
+		extractor.writeHTML(&content, n)
+		extractor.writeText(&text, n)
+	}
+
+	return MainContent{
+		Title:       extractor.title(doc),
+		Byline:      extractor.byline(doc),
+		PublishedAt: extractor.publishedAt(doc, nodes),
+		Text:        cleanText(text.String()),
+		HTML:        content.String(),
+	}, nil
+}
+
+type readability struct {
+	resource *WebResource
+	base     *url.URL
+	scores   map[*html.Node]float64
+}
+
+// mainNodes returns the best scored element and its related siblings.
+func (extractor readability) mainNodes(doc *html.Node) []*html.Node {
+	// Candidates in document order, for ties to be broken the same way
+	// every time
+	candidates := make([]*html.Node, 0)
+
+	for _, n := range htmlquery.Find(doc, "//p | //pre | //td | //blockquote | //div") {
+		if extractor.isRemoved(n) || n.Data == "div" && !hasOwnText(n) {
+			continue
+		}
+
+		text := extractor.text(n)
+		if len(text) < 25 {
+			continue
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + minFloat(float64(len(text))/100, 3)
+		ancestor := n.Parent
+		for level := 0; ancestor != nil && ancestor.Type == html.ElementNode && level < 3; level++ {
+			if _, ok := extractor.scores[ancestor]; !ok {
+				extractor.scores[ancestor] = initialScore(ancestor)
+				candidates = append(candidates, ancestor)
+			}
+
+			switch level {
+			case 0:
+				extractor.scores[ancestor] += score
+			case 1:
+				extractor.scores[ancestor] += score / 2
+			default:
+				extractor.scores[ancestor] += score / float64(level*3)
+			}
+			ancestor = ancestor.Parent
+		}
+	}
+
+	var top *html.Node
+	topScore := 0.0
+	for _, n := range candidates {
+		score := extractor.scores[n] * (1 - extractor.linkDensity(n))
+		extractor.scores[n] = score
+		if top == nil || score > topScore {
+			top, topScore = n, score
+		}
+	}
+
+	if top == nil {
+		if body := htmlquery.FindOne(doc, "//body"); body != nil {
+			return []*html.Node{body}
+		}
+		return []*html.Node{}
+	}
+
+	if top.Parent == nil {
+		return []*html.Node{top}
+	}
+
+	nodes := make([]*html.Node, 0)
+	threshold := maxFloat(10, topScore*0.2)
+	for sibling := top.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling.Type != html.ElementNode || extractor.isRemoved(sibling) {
+			continue
+		}
+
+		related := sibling == top
+		if score, ok := extractor.scores[sibling]; ok && score >= threshold {
+			related = true
+		}
+		if sibling.Data == "p" {
+			text := extractor.text(sibling)
+			related = related || len(text) > 80 && extractor.linkDensity(sibling) < 0.25
+		}
+
+		if related {
+			nodes = append(nodes, sibling)
+		}
+	}
+
+	return nodes
+}
+
+// hasOwnText reports whether n has text children, as divs used as
+// paragraphs do.
+func hasOwnText(n *html.Node) bool {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode && strings.TrimSpace(child.Data) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isRemoved reports whether n or one of its ancestors is boilerplate.
+func (extractor readability) isRemoved(n *html.Node) bool {
+	for ; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		if isBoilerplate(n) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isBoilerplate(n *html.Node) bool {
+	switch n.Data {
+	case "script", "style", "noscript", "nav", "footer", "aside", "form", "iframe", "button", "svg", "template", "object", "embed", "select", "input", "textarea", "header":
+		return true
+	case "html", "body", "article", "main":
+		return false
+	}
+
+	switch strings.ToLower(htmlquery.SelectAttr(n, "role")) {
+	case "navigation", "banner", "contentinfo", "complementary", "search", "dialog":
+		return true
+	}
+
+	if hasAttribute(n, "hidden") || strings.Contains(strings.ReplaceAll(htmlquery.SelectAttr(n, "style"), " ", ""), "display:none") {
+		return true
+	}
+
+	classAndId := strings.ToLower(htmlquery.SelectAttr(n, "class") + " " + htmlquery.SelectAttr(n, "id"))
+	return unlikelyCandidates.MatchString(classAndId) && !likelyCandidates.MatchString(classAndId)
+}
+
+// initialScore favours elements usually holding content over lists and
+// headings, and weights the class and id of the element.
+func initialScore(n *html.Node) float64 {
+	score := 0.0
+
+	switch n.Data {
+	case "div", "article", "main":
+		score = 5
+	case "pre", "td", "blockquote":
+		score = 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		score = -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score = -5
+	}
+
+	classAndId := strings.ToLower(htmlquery.SelectAttr(n, "class") + " " + htmlquery.SelectAttr(n, "id"))
+	if likelyCandidates.MatchString(classAndId) {
+		score += 25
+	}
+	if unlikelyCandidates.MatchString(classAndId) {
+		score -= 25
+	}
+
+	return score
+}
+
+// text returns the text of n without its boilerplate, spaces collapsed.
+func (extractor readability) text(n *html.Node) string {
+	var text strings.Builder
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			text.WriteString(n.Data)
+			text.WriteByte(' ')
+		case n.Type == html.ElementNode && isBoilerplate(n):
+		default:
+			for child := n.FirstChild; child != nil; child = child.NextSibling {
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+
+	return strings.Join(strings.Fields(text.String()), " ")
+}
+
+// linkDensity is the share of the text of n that is the text of links.
+func (extractor readability) linkDensity(n *html.Node) float64 {
+	length := len(extractor.text(n))
+	if length == 0 {
+		return 0
+	}
+
+	linkLength := 0
+	for _, link := range htmlquery.Find(n, ".//a") {
+		linkLength += len(extractor.text(link))
+	}
+
+	return float64(linkLength) / float64(length)
+}
+
+// writeHTML renders n as simplified HTML, dropping boilerplate, attributes
+// other than href, src and alt, and unwrapping unknown elements.
+func (extractor readability) writeHTML(content *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		content.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if isBoilerplate(n) {
+		return
+	}
+
+	tag := ""
+	switch n.Data {
+	case "p", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "li", "blockquote", "pre", "code", "em", "strong", "b", "i",
+		"table", "thead", "tbody", "tr", "th", "td", "figure", "figcaption", "dl", "dt", "dd":
+		tag = n.Data
+	case "br", "hr":
+		content.WriteString("<" + n.Data + ">")
+		return
+	case "img":
+		if src := extractor.absolute(htmlquery.SelectAttr(n, "src")); src != "" {
+			content.WriteString(`<img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(htmlquery.SelectAttr(n, "alt")) + `">`)
+		}
+		return
+	case "a":
+		if href := extractor.absolute(htmlquery.SelectAttr(n, "href")); href != "" {
+			content.WriteString(`<a href="` + html.EscapeString(href) + `">`)
+			extractor.writeChildrenHTML(content, n)
+			content.WriteString("</a>")
+			return
+		}
+	}
+
+	if tag == "" {
+		extractor.writeChildrenHTML(content, n)
+		return
+	}
+
+	content.WriteString("<" + tag + ">")
+	extractor.writeChildrenHTML(content, n)
+	content.WriteString("</" + tag + ">")
+}
+
+func (extractor readability) writeChildrenHTML(content *strings.Builder, n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		extractor.writeHTML(content, child)
+	}
+}
+
+// writeText renders n as text, blocks separated by blank lines.
+func (extractor readability) writeText(text *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		text.WriteString(spaces.ReplaceAllString(n.Data, " "))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if isBoilerplate(n) {
+		return
+	}
+
+	switch n.Data {
+	case "br":
+		text.WriteString("\n")
+		return
+	case "p", "div", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "blockquote", "pre", "table", "tr", "figure", "section", "article", "dl":
+		text.WriteString("\n\n")
+		defer text.WriteString("\n\n")
+	case "li", "dt", "dd":
+		text.WriteString("\n")
+		defer text.WriteString("\n")
+	case "td", "th":
+		defer text.WriteString(" ")
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		extractor.writeText(text, child)
+	}
+}
+
+// title returns the Open Graph title of the page, or its <title> without the
+// name of the site.
+func (extractor readability) title(doc *html.Node) string {
+	if og := htmlquery.FindOne(doc, "//meta[@property='og:title']/@content"); og != nil {
+		if title := strings.TrimSpace(htmlquery.InnerText(og)); title != "" {
+			return title
+		}
+	}
+
+	node := htmlquery.FindOne(doc, "//title")
+	if node == nil {
+		if heading := htmlquery.FindOne(doc, "//h1"); heading != nil {
+			return extractor.text(heading)
+		}
+		return ""
+	}
+
+	// "Article title | Site name" : keep the longest part
+	title := ""
+	for _, part := range titleSeparators.Split(extractor.text(node), -1) {
+		if len(part) > len(title) {
+			title = strings.TrimSpace(part)
+		}
+	}
+
+	return title
+}
+
+// byline returns the author of the page, from its metadata, structured data
+// or an element whose class or id mentions a byline.
+func (extractor readability) byline(doc *html.Node) string {
+	for _, expr := range []string{"//meta[@name='author']/@content", "//meta[@property='article:author']/@content", "//*[@itemprop='author']", "//*[@rel='author']"} {
+		if node := htmlquery.FindOne(doc, expr); node != nil {
+			if author := extractor.text(node); author != "" {
+				return author
+			}
+		}
+	}
+
+	if articles, err := extractor.resource.Articles(); err == nil {
+		for _, article := range articles {
+			if len(article.Authors) > 0 {
+				return strings.Join(article.Authors, ", ")
+			}
+		}
+	}
+
+	for _, node := range htmlquery.Find(doc, "//*[@class or @id]") {
+		classAndId := strings.ToLower(htmlquery.SelectAttr(node, "class") + " " + htmlquery.SelectAttr(node, "id"))
+		if !bylineCandidates.MatchString(classAndId) {
+			continue
+		}
+
+		if byline := extractor.text(node); byline != "" && len(byline) < 100 {
+			return byline
+		}
+	}
+
+	return ""
+}
+
+// publishedAt returns the publish date of the page, from its metadata,
+// structured data or the first <time> of its main content.
+func (extractor readability) publishedAt(doc *html.Node, nodes []*html.Node) time.Time {
+	dates := make([]string, 0)
+
+	for _, expr := range []string{"//meta[@property='article:published_time']/@content", "//meta[@itemprop='datePublished']/@content", "//*[@itemprop='datePublished']/@datetime"} {
+		if node := htmlquery.FindOne(doc, expr); node != nil {
+			dates = append(dates, htmlquery.InnerText(node))
+		}
+	}
+
+	if articles, err := extractor.resource.Articles(); err == nil {
+		for _, article := range articles {
+			dates = append(dates, article.DatePublished)
+		}
+	}
+
+	for _, n := range nodes {
+		if node := htmlquery.FindOne(n, "//time/@datetime"); node != nil {
+			dates = append(dates, htmlquery.InnerText(node))
+		}
+	}
+
+	for _, date := range dates {
+		if publishedAt, ok := parseDate(strings.TrimSpace(date)); ok {
+			return publishedAt
+		}
+	}
+
+	return time.Time{}
+}
+
+func (extractor readability) absolute(rawUrl string) string {
+	if strings.TrimSpace(rawUrl) == "" {
+		return ""
+	}
+
+	resolvedUrl, err := extractor.resource.resolveUrl(rawUrl, extractor.base)
+	if err != nil {
+		return ""
+	}
+
+	return resolvedUrl
+}
+
+// parseDate parses the ISO 8601 dates found in pages.
+func parseDate(date string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05Z0700", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02"} {
+		if parsed, err := time.Parse(layout, date); err == nil {
+			return parsed, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// cleanText collapses the spaces of every line and the blank lines between
+// paragraphs.
+func cleanText(text string) string {
+	lines := strings.Split(text, "\n")
+	for index, line := range lines {
+		lines[index] = strings.Join(strings.Fields(line), " ")
+	}
+
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+func minFloat(a float64, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a float64, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const articleContent = `
+<html>
+	<head>
+		<title>Why crawlers should be polite | Example Blog</title>
+		<meta name="author" content="Alice Martin">
+		<meta property="article:published_time" content="2020-05-01T08:00:00+02:00">
+		<style>body { color: red; }</style>
+	</head>
+	<body>
+		<nav><a href="/">Home</a> <a href="/blog">Blog</a> <a href="/about">About us, our team, our history</a></nav>
+		<div class="ad-banner">Buy our product now, it is great, cheap and fast, really.</div>
+		<div id="content">
+			<h1>Why crawlers should be polite</h1>
+			<p>Crawlers send a lot of requests, and small sites may not cope with them. Being polite means spacing requests.</p>
+			<p>The robots.txt file tells crawlers which pages they may fetch, and how long to wait between two requests.</p>
+			<img src="/img/polite.png" alt="A polite crawler">
+			<p>Respecting these rules keeps the web healthy, and it <a href="/rules">avoids getting banned</a> as well.</p>
+			<script>track("article");</script>
+			<div class="share">Share on social networks, tweet it, post it, like it, now.</div>
+		</div>
+		<aside class="sidebar"><p>Related posts, more posts, even more posts, and so on and so forth.</p></aside>
+		<footer><p>Copyright Example Blog, all rights reserved, since forever and ever.</p></footer>
+	</body>
+</html>
+`
+
+func TestMainText(t *testing.T) {
+	webResource, err := NewWebResource("https://example.com/blog/polite", "text/html", []byte(articleContent))
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+
+	content, err := webResource.MainText()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if content.Title != "Why crawlers should be polite" || content.Byline != "Alice Martin" {
+		t.Logf("Wrong title %q or byline %q", content.Title, content.Byline)
+		t.Fail()
+	}
+
+	if !content.PublishedAt.Equal(time.Date(2020, 5, 1, 6, 0, 0, 0, time.UTC)) {
+		t.Logf("Wrong publish date %s", content.PublishedAt)
+		t.Fail()
+	}
+
+	expectedText := "Why crawlers should be polite\n\n" +
+		"Crawlers send a lot of requests, and small sites may not cope with them. Being polite means spacing requests.\n\n" +
+		"The robots.txt file tells crawlers which pages they may fetch, and how long to wait between two requests.\n\n" +
+		"Respecting these rules keeps the web healthy, and it avoids getting banned as well."
+	if content.Text != expectedText {
+		t.Logf("Text %q different of %q", content.Text, expectedText)
+		t.Fail()
+	}
+
+	for _, expected := range []string{`<img src="https://example.com/img/polite.png" alt="A polite crawler">`, `<a href="https://example.com/rules">avoids getting banned</a>`, "<h1>Why crawlers should be polite</h1>"} {
+		if !strings.Contains(content.HTML, expected) {
+			t.Logf("HTML %s should contain %s", content.HTML, expected)
+			t.Fail()
+		}
+	}
+
+	for _, boilerplate := range []string{"Home", "Buy our product", "track(", "Share on", "Related posts", "Copyright", "color: red"} {
+		if strings.Contains(content.Text, boilerplate) || strings.Contains(content.HTML, boilerplate) {
+			t.Logf("Boilerplate %q should be stripped", boilerplate)
+			t.Fail()
+		}
+	}
+}
+
+func TestMainTextFallsBackToBody(t *testing.T) {
+	webResource, _ := NewWebResource("https://example.com/", "text/html", []byte("<html><body><h1>Short</h1><nav>menu</nav></body></html>"))
+
+	content, err := webResource.MainText()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if content.Text != "Short" || content.Title != "Short" || !content.PublishedAt.IsZero() {
+		t.Logf("Wrong content %+v", content)
+		t.Fail()
+	}
+}
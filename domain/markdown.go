@@ -0,0 +1,391 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+var (
+	markdownSpecialCharacters = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`)
+	hardBreakIndents          = regexp.MustCompile(`  \n[ \t]+`)
+)
+
+// Markdown converts the body of the page to GitHub Flavored Markdown.
+// Headings, paragraphs, lists, quotes, code blocks, tables, links and images
+// are kept, with absolute urls. Scripts, styles and forms are dropped.
+func (resource *WebResource) Markdown() (string, error) {
+	doc, err := resource.document()
+
+	if err != nil {
+		return "", err
+	}
+
+	base := resource.baseUrl(doc)
+	converter := markdownConverter{resolve: func(rawUrl string) string {
+		resolvedUrl, err := resource.resolveUrl(rawUrl, base)
+		if err != nil {
+			return rawUrl
+		}
+		return resolvedUrl
+	}}
+
+	root := doc
+	if body := htmlquery.FindOne(doc, "//body"); body != nil {
+		root = body
+	}
+
+	return converter.convert(root), nil
+}
+
+// HTMLToMarkdown converts an HTML fragment, such as MainContent.HTML, to
+// GitHub Flavored Markdown. Urls are kept as written.
+func HTMLToMarkdown(fragment string) (string, error) {
+	doc, err := htmlquery.Parse(strings.NewReader(fragment))
+
+	if err != nil {
+		return "", err
+	}
+
+	converter := markdownConverter{resolve: func(rawUrl string) string { return rawUrl }}
+
+	root := doc
+	if body := htmlquery.FindOne(doc, "//body"); body != nil {
+		root = body
+	}
+
+	return converter.convert(root), nil
+}
+
+type markdownConverter struct {
+	resolve func(rawUrl string) string
+}
+
+func (converter markdownConverter) convert(n *html.Node) string {
+	markdown := converter.blocks(n, "\n\n")
+	markdown = hardBreakIndents.ReplaceAllString(markdown, "  \n")
+
+	return strings.TrimSpace(blankLines.ReplaceAllString(markdown, "\n\n")) + "\n"
+}
+
+// blocks converts the children of n, runs of inline content becoming
+// paragraphs. Blocks are joined with separator.
+func (converter markdownConverter) blocks(n *html.Node, separator string) string {
+	parts := make([]string, 0)
+	var inline strings.Builder
+
+	flush := func() {
+		if text := strings.TrimSpace(inline.String()); text != "" {
+			parts = append(parts, text)
+		}
+		inline.Reset()
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && isMarkdownBlock(child.Data) {
+			flush()
+			if block := converter.block(child); block != "" {
+				parts = append(parts, block)
+			}
+			continue
+		}
+
+		inline.WriteString(converter.inline(child))
+	}
+	flush()
+
+	return strings.Join(parts, separator)
+}
+
+func (converter markdownConverter) block(n *html.Node) string {
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level, _ := strconv.Atoi(n.Data[1:])
+		text := strings.Join(strings.Fields(converter.inlineChildren(n)), " ")
+		if text == "" {
+			return ""
+		}
+		return strings.Repeat("#", level) + " " + text
+	case "ul", "ol":
+		return converter.list(n)
+	case "blockquote":
+		return quoteLines(converter.blocks(n, "\n\n"))
+	case "pre":
+		return converter.codeBlock(n)
+	case "table":
+		return converter.table(n)
+	case "hr":
+		return "---"
+	}
+
+	if isMarkdownSkipped(n.Data) {
+		return ""
+	}
+
+	return converter.blocks(n, "\n\n")
+}
+
+func (converter markdownConverter) inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return markdownSpecialCharacters.Replace(spaces.ReplaceAllString(n.Data, " "))
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.Data {
+	case "br":
+		return "  \n"
+	case "em", "i":
+		return wrapInline(converter.inlineChildren(n), "_")
+	case "strong", "b":
+		return wrapInline(converter.inlineChildren(n), "**")
+	case "del", "s", "strike":
+		return wrapInline(converter.inlineChildren(n), "~~")
+	case "code", "kbd", "samp", "tt":
+		return inlineCode(htmlquery.InnerText(n))
+	case "img":
+		src := htmlquery.SelectAttr(n, "src")
+		if src == "" {
+			return ""
+		}
+		return fmt.Sprintf("![%s](%s)", markdownSpecialCharacters.Replace(htmlquery.SelectAttr(n, "alt")), markdownUrl(converter.resolve(src)))
+	case "a":
+		text := converter.inlineChildren(n)
+		href := htmlquery.SelectAttr(n, "href")
+		if href == "" || strings.HasPrefix(href, "javascript:") || strings.TrimSpace(text) == "" {
+			return text
+		}
+
+		destination := markdownUrl(converter.resolve(href))
+		if title := htmlquery.SelectAttr(n, "title"); title != "" {
+			destination += ` "` + strings.ReplaceAll(title, `"`, `\"`) + `"`
+		}
+		return wrapInline(text, "[", "]("+destination+")")
+	}
+
+	if isMarkdownSkipped(n.Data) {
+		return ""
+	}
+
+	return converter.inlineChildren(n)
+}
+
+func (converter markdownConverter) inlineChildren(n *html.Node) string {
+	var text strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		text.WriteString(converter.inline(child))
+	}
+
+	return text.String()
+}
+
+func (converter markdownConverter) list(n *html.Node) string {
+	items := make([]string, 0)
+	number := 1
+	if start, err := strconv.Atoi(htmlquery.SelectAttr(n, "start")); err == nil {
+		number = start
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.Data != "li" {
+			continue
+		}
+
+		marker := "- "
+		if n.Data == "ol" {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+
+		content := converter.blocks(child, "\n")
+		items = append(items, prefixLines(content, marker, strings.Repeat(" ", len(marker))))
+	}
+
+	return strings.Join(items, "\n")
+}
+
+// codeBlock converts a <pre> to a fenced code block, its language taken from
+// a language-* or lang-* class.
+func (converter markdownConverter) codeBlock(n *html.Node) string {
+	language := codeLanguage(n)
+	for child := n.FirstChild; child != nil && language == ""; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == "code" {
+			language = codeLanguage(child)
+		}
+	}
+
+	code := strings.TrimRight(htmlquery.InnerText(n), "\n")
+
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+
+	return fence + language + "\n" + code + "\n" + fence
+}
+
+// table converts a <table> to a GitHub Flavored Markdown table, its first row
+// being the header.
+func (converter markdownConverter) table(n *html.Node) string {
+	rows := make([][]string, 0)
+	columns := 0
+
+	for _, row := range htmlquery.Find(n, ".//tr") {
+		if closestTable(row) != n {
+			continue
+		}
+
+		cells := make([]string, 0)
+		for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.Type == html.ElementNode && (cell.Data == "td" || cell.Data == "th") {
+				text := strings.Join(strings.Fields(converter.inlineChildren(cell)), " ")
+				cells = append(cells, strings.ReplaceAll(text, "|", `\|`))
+			}
+		}
+
+		if len(cells) > columns {
+			columns = len(cells)
+		}
+		rows = append(rows, cells)
+	}
+
+	if len(rows) == 0 || columns == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(rows)+1)
+	for index, cells := range rows {
+		for len(cells) < columns {
+			cells = append(cells, "")
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+
+		if index == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func closestTable(n *html.Node) *html.Node {
+	for n = n.Parent; n != nil; n = n.Parent {
+		if n.Type == html.ElementNode && n.Data == "table" {
+			return n
+		}
+	}
+
+	return nil
+}
+
+func codeLanguage(n *html.Node) string {
+	for _, class := range strings.Fields(htmlquery.SelectAttr(n, "class")) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if strings.HasPrefix(class, prefix) {
+				return strings.TrimPrefix(class, prefix)
+			}
+		}
+	}
+
+	return ""
+}
+
+// wrapInline surrounds text with the opening and closing markers, outside of
+// its surrounding spaces. The closing marker defaults to the opening one.
+func wrapInline(text string, markers ...string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+
+	opening, closing := markers[0], markers[0]
+	if len(markers) > 1 {
+		closing = markers[1]
+	}
+
+	leading := text[:strings.Index(text, trimmed)]
+	trailing := text[len(leading)+len(trimmed):]
+
+	return leading + opening + trimmed + closing + trailing
+}
+
+// inlineCode surrounds code with more backticks than it contains in a row.
+func inlineCode(code string) string {
+	code = strings.ReplaceAll(code, "\n", " ")
+	if strings.TrimSpace(code) == "" {
+		return ""
+	}
+
+	fence := "`"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		return fence + " " + code + " " + fence
+	}
+
+	return fence + code + fence
+}
+
+// markdownUrl makes url usable as a link destination.
+func markdownUrl(url string) string {
+	if strings.ContainsAny(url, " ()") {
+		return "<" + url + ">"
+	}
+
+	return url
+}
+
+// prefixLines prefixes the first line of text with first and the following
+// non-empty ones with rest.
+func prefixLines(text string, first string, rest string) string {
+	lines := strings.Split(text, "\n")
+	for index, line := range lines {
+		if index == 0 {
+			lines[index] = first + line
+		} else if line != "" {
+			lines[index] = rest + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func quoteLines(text string) string {
+	lines := strings.Split(text, "\n")
+	for index, line := range lines {
+		if line == "" {
+			lines[index] = ">"
+		} else {
+			lines[index] = "> " + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func isMarkdownBlock(tag string) bool {
+	switch tag {
+	case "address", "article", "aside", "blockquote", "body", "details", "dd", "div", "dl", "dt", "fieldset", "figcaption", "figure",
+		"footer", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hr", "html", "li", "main", "nav", "ol", "p", "pre", "section", "summary", "table", "ul":
+		return true
+	}
+
+	return isMarkdownSkipped(tag)
+}
+
+func isMarkdownSkipped(tag string) bool {
+	switch tag {
+	case "head", "script", "style", "noscript", "template", "iframe", "svg", "canvas", "button", "form", "input", "select", "textarea", "object", "embed":
+		return true
+	}
+
+	return false
+}
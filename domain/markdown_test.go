@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	strContent := `
+	<html>
+		<head><title>Ignored</title><style>p { color: red; }</style></head>
+		<body>
+			<h1>Getting <em>started</em></h1>
+			<p>Read the <a href="../guide" title="The guide">full guide</a> or
+			the <strong>short</strong> one, it uses *stars* and <code>go test</code>.<br>
+			New line.</p>
+			<img src="/img/logo.png" alt="Logo">
+			<ul>
+				<li>First</li>
+				<li>Second
+					<ol start="3">
+						<li>Nested</li>
+						<li><a href="https://example.org/">External</a></li>
+					</ol>
+				</li>
+			</ul>
+			<blockquote><p>Quoted</p><p>Twice</p></blockquote>
+			<pre><code class="language-go">func main() {
+	fmt.Println("hi")
+}
+</code></pre>
+			<table>
+				<thead><tr><th>Name</th><th>Price</th></tr></thead>
+				<tbody>
+					<tr><td>Gopher | plush</td><td>12.50</td></tr>
+					<tr><td>Sticker</td></tr>
+				</tbody>
+			</table>
+			<script>alert("ignored")</script>
+			<hr>
+			<div><p>Last</p></div>
+		</body>
+	</html>
+	`
+	webResource, err := NewWebResource("https://example.com/docs/start", "text/html", []byte(strContent))
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+
+	markdown, err := webResource.Markdown()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	expected := "# Getting _started_\n\n" +
+		"Read the [full guide](https://example.com/guide \"The guide\") or the **short** one, it uses \\*stars\\* and `go test`.  \n" +
+		"New line.\n\n" +
+		"![Logo](https://example.com/img/logo.png)\n\n" +
+		"- First\n" +
+		"- Second\n" +
+		"  3. Nested\n" +
+		"  4. [External](https://example.org/)\n\n" +
+		"> Quoted\n" +
+		">\n" +
+		"> Twice\n\n" +
+		"```go\n" +
+		"func main() {\n" +
+		"\tfmt.Println(\"hi\")\n" +
+		"}\n" +
+		"```\n\n" +
+		"| Name | Price |\n" +
+		"| --- | --- |\n" +
+		"| Gopher \\| plush | 12.50 |\n" +
+		"| Sticker |  |\n\n" +
+		"---\n\n" +
+		"Last\n"
+
+	if markdown != expected {
+		t.Logf("Markdown\n%s\ndifferent of\n%s", markdown, expected)
+		t.Fail()
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	markdown, err := HTMLToMarkdown(`<p>Keep <a href="/relative">relative</a> links, code with a <code>` + "`" + `tick</code></p><pre>a ` + "```" + ` fence</pre>`)
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	expected := "Keep [relative](/relative) links, code with a `` `tick ``\n\n" +
+		"````\n" +
+		"a ``` fence\n" +
+		"````\n"
+
+	if markdown != expected {
+		t.Logf("Markdown\n%s\ndifferent of\n%s", markdown, expected)
+		t.Fail()
+	}
+}
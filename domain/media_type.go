@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ErrNotHTML is returned by the extractors of resources that are not HTML
+// pages.
+var ErrNotHTML = errors.New("resource is not an HTML page")
+
+// MediaType returns the lowercased media type of the resource, without its
+// parameters, e.g. "text/html".
+// When the Content-Type is missing, cannot be parsed or is a generic one
+// such as application/octet-stream, the media type is sniffed from the
+// content with the algorithm of http.DetectContentType.
+func (resource WebResource) MediaType() string {
+	mediaType, _, err := mime.ParseMediaType(resource.contentType)
+
+	if err != nil || isGenericMediaType(mediaType) {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(resource.rawContent))
+	}
+
+	return mediaType
+}
+
+func isGenericMediaType(mediaType string) bool {
+	switch mediaType {
+	case "", "application/octet-stream", "application/unknown", "unknown/unknown", "*/*":
+		return true
+	}
+
+	return false
+}
+
+func isHTMLMediaType(mediaType string) bool {
+	switch mediaType {
+	case "text/html", "text/htm", "application/xhtml+xml":
+		return true
+	}
+
+	return strings.HasSuffix(mediaType, "+html")
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMediaType(t *testing.T) {
+	page := []byte("<!DOCTYPE html><html><body><a href=\"/\">home</a></body></html>")
+	png := []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+	cases := []struct {
+		contentType string
+		content     []byte
+		mediaType   string
+		isWebPage   bool
+	}{
+		{"text/html", page, "text/html", true},
+		{"TEXT/HTML; charset=UTF-8", page, "text/html", true},
+		{"application/xhtml+xml", page, "application/xhtml+xml", true},
+		{"", page, "text/html", true},
+		{"application/octet-stream", page, "text/html", true},
+		{"not a media type", page, "text/html", true},
+		{"image/png", png, "image/png", false},
+		{"application/octet-stream", png, "image/png", false},
+		{"", png, "image/png", false},
+		{"text/plain", page, "text/plain", false},
+		{"application/json", []byte(`{"text/html": true}`), "application/json", false},
+	}
+
+	for _, c := range cases {
+		webResource, err := NewWebResource("https://example.com/", c.contentType, c.content)
+		if err != nil {
+			t.Log("Could not create WebResource")
+			t.FailNow()
+		}
+
+		if webResource.MediaType() != c.mediaType {
+			t.Logf("%q : media type %s different of %s", c.contentType, webResource.MediaType(), c.mediaType)
+			t.Fail()
+		}
+
+		if webResource.IsWebPage() != c.isWebPage {
+			t.Logf("%q : IsWebPage should be %t", c.contentType, c.isWebPage)
+			t.Fail()
+		}
+	}
+}
+
+func TestExtractorsOnNonHTML(t *testing.T) {
+	webResource, err := NewWebResource("https://example.com/logo.png", "image/png", []byte("\x89PNG\x0D\x0A\x1A\x0A"))
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+
+	extractors := []func() ([]string, error){
+		webResource.LinksUrls,
+		webResource.InternalLinksUrls,
+		webResource.ImagesUrls,
+		webResource.StyleSheetsUrls,
+		webResource.JavascriptUrls,
+	}
+
+	for _, extractor := range extractors {
+		urls, err := extractor()
+
+		if !errors.Is(err, ErrNotHTML) {
+			t.Logf("Expected ErrNotHTML, got %v", err)
+			t.Fail()
+		}
+
+		if len(urls) != 0 {
+			t.Logf("No urls should be extracted, got %s", urls)
+			t.Fail()
+		}
+	}
+
+	if selection := webResource.SelectCSS("a"); !errors.Is(selection.Err(), ErrNotHTML) {
+		t.Logf("Expected ErrNotHTML, got %v", selection.Err())
+		t.Fail()
+	}
+}
@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// MicrodataItem is an element with an itemscope attribute.
+// Property values are either strings or nested *MicrodataItem.
+type MicrodataItem struct {
+	Types      []string
+	ID         string
+	Properties map[string][]interface{}
+}
+
+// Property returns the first value of the property name as a string, or an
+// empty string when the item does not have it or it is a nested item.
+func (item *MicrodataItem) Property(name string) string {
+	for _, value := range item.Properties[name] {
+		if text, ok := value.(string); ok {
+			return text
+		}
+	}
+
+	return ""
+}
+
+// Item returns the first nested item of the property name, or nil.
+func (item *MicrodataItem) Item(name string) *MicrodataItem {
+	for _, value := range item.Properties[name] {
+		if nested, ok := value.(*MicrodataItem); ok {
+			return nested
+		}
+	}
+
+	return nil
+}
+
+// Microdata returns the top-level items of the page, those that are not the
+// value of a property of another item. Url properties are made absolute.
+func (resource *WebResource) Microdata() ([]*MicrodataItem, error) {
+	items := make([]*MicrodataItem, 0)
+	doc, err := resource.document()
+
+	if err != nil {
+		return items, err
+	}
+
+	parser := microdataParser{resource: resource, doc: doc, base: resource.baseUrl(doc)}
+	for _, n := range htmlquery.Find(doc, "//*[@itemscope and not(@itemprop)]") {
+		items = append(items, parser.item(n, map[*html.Node]bool{}))
+	}
+
+	return items, nil
+}
+
+type microdataParser struct {
+	resource *WebResource
+	doc      *html.Node
+	base     *url.URL
+}
+
+// item builds the item of the itemscope element n. visited guards against
+// itemref cycles.
+func (parser microdataParser) item(n *html.Node, visited map[*html.Node]bool) *MicrodataItem {
+	visited[n] = true
+	item := &MicrodataItem{
+		Types:      strings.Fields(htmlquery.SelectAttr(n, "itemtype")),
+		ID:         htmlquery.SelectAttr(n, "itemid"),
+		Properties: make(map[string][]interface{}),
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		parser.collect(item, child, visited)
+	}
+
+	// Elements listed in itemref hold properties of the item too
+	for _, id := range strings.Fields(htmlquery.SelectAttr(n, "itemref")) {
+		if referenced := htmlquery.FindOne(parser.doc, "//*[@id="+xpathLiteral(id)+"]"); referenced != nil {
+			parser.collect(item, referenced, visited)
+		}
+	}
+
+	return item
+}
+
+// collect adds the properties found in n and its descendants to item,
+// without going into nested items.
+func (parser microdataParser) collect(item *MicrodataItem, n *html.Node, visited map[*html.Node]bool) {
+	if n.Type != html.ElementNode {
+		return
+	}
+
+	parser.addProperties(item, n, visited)
+
+	if hasAttribute(n, "itemscope") {
+		return
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		parser.collect(item, child, visited)
+	}
+}
+
+func (parser microdataParser) addProperties(item *MicrodataItem, n *html.Node, visited map[*html.Node]bool) {
+	names := strings.Fields(htmlquery.SelectAttr(n, "itemprop"))
+	if len(names) == 0 {
+		return
+	}
+
+	var value interface{}
+	if hasAttribute(n, "itemscope") {
+		if visited[n] {
+			return
+		}
+		value = parser.item(n, visited)
+	} else {
+		value = parser.value(n)
+	}
+
+	for _, name := range names {
+		item.Properties[name] = append(item.Properties[name], value)
+	}
+}
+
+// value returns the property value of a non-item element, as defined by the
+// HTML standard.
+func (parser microdataParser) value(n *html.Node) string {
+	attribute := ""
+	isUrl := false
+
+	switch n.Data {
+	case "meta":
+		attribute = "content"
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		attribute, isUrl = "src", true
+	case "a", "area", "link":
+		attribute, isUrl = "href", true
+	case "object":
+		attribute, isUrl = "data", true
+	case "data", "meter":
+		attribute = "value"
+	case "time":
+		if hasAttribute(n, "datetime") {
+			attribute = "datetime"
+		}
+	}
+
+	if attribute == "" {
+		return strings.TrimSpace(htmlquery.InnerText(n))
+	}
+
+	value := htmlquery.SelectAttr(n, attribute)
+	if isUrl && value != "" {
+		if resolvedUrl, err := parser.resource.resolveUrl(value, parser.base); err == nil {
+			return resolvedUrl
+		}
+	}
+
+	return value
+}
+
+func hasAttribute(n *html.Node, name string) bool {
+	_, ok := nodeAttr(n, name)
+
+	return ok
+}
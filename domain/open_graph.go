@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+)
+
+// OpenGraphNamespaces are the prefixes of the <meta property> tags returned
+// by OpenGraph.
+var OpenGraphNamespaces = []string{"og", "fb", "article", "book", "profile", "music", "video", "product"}
+
+// OpenGraph returns the content of the Open Graph <meta property> tags of the
+// page by property, e.g. "og:title" or "article:author". Properties such as
+// "og:image" may be repeated and keep the order of the page.
+func (resource *WebResource) OpenGraph() (map[string][]string, error) {
+	properties := make(map[string][]string)
+	doc, err := resource.document()
+
+	if err != nil {
+		return properties, err
+	}
+
+	for _, n := range htmlquery.Find(doc, "//meta[@property and @content]") {
+		property := strings.ToLower(strings.TrimSpace(htmlquery.SelectAttr(n, "property")))
+		if !isOpenGraphProperty(property) {
+			continue
+		}
+
+		properties[property] = append(properties[property], htmlquery.SelectAttr(n, "content"))
+	}
+
+	return properties, nil
+}
+
+// TwitterCard returns the content of the twitter:* <meta> tags of the page
+// by name, e.g. "twitter:card". Both the name and the property attributes
+// are looked at, and the first tag wins.
+func (resource *WebResource) TwitterCard() (map[string]string, error) {
+	card := make(map[string]string)
+	doc, err := resource.document()
+
+	if err != nil {
+		return card, err
+	}
+
+	for _, n := range htmlquery.Find(doc, "//meta[@content]") {
+		name := htmlquery.SelectAttr(n, "name")
+		if name == "" {
+			name = htmlquery.SelectAttr(n, "property")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if _, ok := card[name]; strings.HasPrefix(name, "twitter:") && !ok {
+			card[name] = htmlquery.SelectAttr(n, "content")
+		}
+	}
+
+	return card, nil
+}
+
+func isOpenGraphProperty(property string) bool {
+	for _, namespace := range OpenGraphNamespaces {
+		if strings.HasPrefix(property, namespace+":") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+)
+
+// PageMetadata gathers the metadata found in the head of a web page.
+type PageMetadata struct {
+	Title       string
+	Description string
+	Keywords    []string
+	// Canonical is the absolute url of <link rel="canonical">.
+	Canonical string
+	// Alternates are the translations given by <link rel="alternate" hreflang>.
+	Alternates []AlternateLink
+	// Next and Prev are the absolute urls of the pages around a paginated one.
+	Next string
+	Prev string
+	// Lang is the lang attribute of <html>.
+	Lang   string
+	Robots RobotsDirectives
+}
+
+// AlternateLink is a version of the page in another language or region.
+type AlternateLink struct {
+	HrefLang string
+	URL      string
+}
+
+// RobotsDirectives are the indexing directives of a page, from its robots
+// <meta> tags and X-Robots-Tag headers.
+type RobotsDirectives struct {
+	NoIndex   bool
+	NoFollow  bool
+	NoArchive bool
+	NoSnippet bool
+	// Directives holds every lowercased directive, known or not.
+	Directives []string
+}
+
+// Metadata returns the title, description, keywords, canonical url,
+// alternates, pagination links, language and robots directives of the page.
+func (resource *WebResource) Metadata() (PageMetadata, error) {
+	metadata := PageMetadata{Keywords: make([]string, 0), Alternates: make([]AlternateLink, 0)}
+	doc, err := resource.document()
+
+	if err != nil {
+		metadata.Robots = resource.Robots()
+		return metadata, err
+	}
+
+	base := resource.baseUrl(doc)
+	resolve := func(rawUrl string) string {
+		resolvedUrl, err := resource.resolveUrl(rawUrl, base)
+		if err != nil {
+			return ""
+		}
+		return resolvedUrl
+	}
+
+	if title := htmlquery.FindOne(doc, "//title"); title != nil {
+		metadata.Title = strings.Join(strings.Fields(htmlquery.InnerText(title)), " ")
+	}
+
+	if html := htmlquery.FindOne(doc, "//html[@lang]"); html != nil {
+		metadata.Lang = strings.TrimSpace(htmlquery.SelectAttr(html, "lang"))
+	}
+
+	for _, n := range htmlquery.Find(doc, "//meta[@name and @content]") {
+		content := htmlquery.SelectAttr(n, "content")
+
+		switch strings.ToLower(strings.TrimSpace(htmlquery.SelectAttr(n, "name"))) {
+		case "description":
+			if metadata.Description == "" {
+				metadata.Description = strings.TrimSpace(content)
+			}
+		case "keywords":
+			for _, keyword := range strings.Split(content, ",") {
+				if keyword = strings.TrimSpace(keyword); keyword != "" {
+					metadata.Keywords = append(metadata.Keywords, keyword)
+				}
+			}
+		}
+	}
+
+	for _, n := range htmlquery.Find(doc, "//link[@rel and @href]") {
+		href := htmlquery.SelectAttr(n, "href")
+
+		for _, rel := range strings.Fields(strings.ToLower(htmlquery.SelectAttr(n, "rel"))) {
+			switch {
+			case rel == "canonical" && metadata.Canonical == "":
+				metadata.Canonical = resolve(href)
+			case rel == "next" && metadata.Next == "":
+				metadata.Next = resolve(href)
+			case rel == "prev" && metadata.Prev == "":
+				metadata.Prev = resolve(href)
+			case rel == "alternate":
+				if hrefLang := htmlquery.SelectAttr(n, "hreflang"); hrefLang != "" {
+					metadata.Alternates = append(metadata.Alternates, AlternateLink{HrefLang: hrefLang, URL: resolve(href)})
+				}
+			}
+		}
+	}
+
+	metadata.Robots = resource.Robots()
+
+	return metadata, nil
+}
+
+// Robots returns the directives of the X-Robots-Tag headers of the resource
+// merged with those of the robots <meta> tags of web pages.
+// Directives aimed at a specific crawler, such as "googlebot: noindex", are
+// ignored.
+func (resource *WebResource) Robots() RobotsDirectives {
+	robots := RobotsDirectives{Directives: make([]string, 0)}
+
+	for _, header := range resource.Header().Values("X-Robots-Tag") {
+		robots.add(header)
+	}
+
+	if doc, err := resource.document(); err == nil {
+		for _, n := range htmlquery.Find(doc, "//meta[@name and @content]") {
+			if strings.ToLower(strings.TrimSpace(htmlquery.SelectAttr(n, "name"))) == "robots" {
+				robots.add(htmlquery.SelectAttr(n, "content"))
+			}
+		}
+	}
+
+	return robots
+}
+
+// add merges the comma separated directives of value.
+func (robots *RobotsDirectives) add(value string) {
+	directives := strings.Split(strings.ToLower(value), ",")
+
+	// A leading "name:" aims the directives at a single crawler
+	if prefix := strings.SplitN(directives[0], ":", 2); len(prefix) == 2 && !isRobotsDirective(strings.TrimSpace(prefix[0])) {
+		if strings.TrimSpace(prefix[0]) != "robots" {
+			return
+		}
+		directives[0] = prefix[1]
+	}
+
+	for _, directive := range directives {
+		directive = strings.TrimSpace(directive)
+		if directive == "" {
+			continue
+		}
+		robots.Directives = append(robots.Directives, directive)
+
+		switch directive {
+		case "noindex":
+			robots.NoIndex = true
+		case "nofollow":
+			robots.NoFollow = true
+		case "noarchive":
+			robots.NoArchive = true
+		case "nosnippet":
+			robots.NoSnippet = true
+		case "none":
+			robots.NoIndex = true
+			robots.NoFollow = true
+		}
+	}
+}
+
+// isRobotsDirective reports whether name is a directive taking a value, as
+// in "max-snippet: 20".
+func isRobotsDirective(name string) bool {
+	switch name {
+	case "unavailable_after", "max-snippet", "max-image-preview", "max-video-preview":
+		return true
+	}
+
+	return false
+}
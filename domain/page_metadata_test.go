@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestMetadata(t *testing.T) {
+	strContent := `
+	<html lang="en-GB">
+		<head>
+			<title>
+				Crawling   guide
+			</title>
+			<meta name="Description" content=" How to crawl. ">
+			<meta name="keywords" content="crawler, go ,, spider">
+			<meta name="robots" content="noarchive">
+			<meta name="googlebot" content="noindex">
+			<link rel="canonical" href="/guide">
+			<link rel="alternate" hreflang="fr" href="/fr/guide">
+			<link rel="alternate" hreflang="x-default" href="https://example.com/guide">
+			<link rel="alternate" type="application/rss+xml" href="/feed">
+			<link rel="next" href="?page=3">
+			<link rel="prev" href="?page=1">
+		</head>
+	</html>
+	`
+	webResource, err := NewWebResource("https://example.com/guide?page=2", "text/html", []byte(strContent))
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+	webResource.ChangeResponseMetadata(ResponseMetadata{Header: http.Header{"X-Robots-Tag": {"nofollow", "otherbot: noindex"}}})
+
+	metadata, err := webResource.Metadata()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if metadata.Title != "Crawling guide" || metadata.Description != "How to crawl." || metadata.Lang != "en-GB" {
+		t.Logf("Wrong title %q, description %q or lang %q", metadata.Title, metadata.Description, metadata.Lang)
+		t.Fail()
+	}
+
+	if !textsMatch([]string{"crawler", "go", "spider"}, metadata.Keywords) {
+		t.Logf("Wrong keywords %s", metadata.Keywords)
+		t.Fail()
+	}
+
+	if metadata.Canonical != "https://example.com/guide" || metadata.Next != "https://example.com/guide?page=3" || metadata.Prev != "https://example.com/guide?page=1" {
+		t.Logf("Wrong canonical %s, next %s or prev %s", metadata.Canonical, metadata.Next, metadata.Prev)
+		t.Fail()
+	}
+
+	expected := []AlternateLink{{"fr", "https://example.com/fr/guide"}, {"x-default", "https://example.com/guide"}}
+	if len(metadata.Alternates) != len(expected) || metadata.Alternates[0] != expected[0] || metadata.Alternates[1] != expected[1] {
+		t.Logf("Alternates %v different of %v", metadata.Alternates, expected)
+		t.Fail()
+	}
+
+	robots := metadata.Robots
+	if robots.NoIndex || !robots.NoFollow || !robots.NoArchive {
+		t.Logf("Wrong robots directives %+v", robots)
+		t.Fail()
+	}
+}
+
+func TestRobotsDirectives(t *testing.T) {
+	cases := []struct {
+		header   string
+		noIndex  bool
+		noFollow bool
+	}{
+		{"none", true, true},
+		{"NoIndex, NoFollow", true, true},
+		{"robots: noindex", true, false},
+		{"googlebot: noindex", false, false},
+		{"max-snippet: 20, nofollow", false, true},
+		{"unavailable_after: 25 Jun 2010 15:00:00 PST", false, false},
+		{"all", false, false},
+	}
+
+	for _, c := range cases {
+		webResource, _ := NewWebResource("https://example.com/file.pdf", "application/pdf", []byte("%PDF-"))
+		webResource.ChangeResponseMetadata(ResponseMetadata{Header: http.Header{"X-Robots-Tag": {c.header}}})
+
+		robots := webResource.Robots()
+		if robots.NoIndex != c.noIndex || robots.NoFollow != c.noFollow {
+			t.Logf("%q : wrong directives %+v", c.header, robots)
+			t.Fail()
+		}
+	}
+
+	webResource, _ := NewWebResource("https://example.com/file.pdf", "application/pdf", []byte("%PDF-"))
+	webResource.ChangeResponseMetadata(ResponseMetadata{Header: http.Header{"X-Robots-Tag": {"noindex"}}})
+
+	metadata, err := webResource.Metadata()
+	if !errors.Is(err, ErrNotHTML) || !metadata.Robots.NoIndex {
+		t.Logf("Metadata of non-HTML resources should hold the header directives : %+v, %v", metadata, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// RDFTypeIRI is the predicate of the triples giving the type of a subject.
+const RDFTypeIRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+
+// RDFaPrefixes are the prefixes known without a prefix attribute, from the
+// RDFa initial context.
+var RDFaPrefixes = map[string]string{
+	"dc":     "http://purl.org/dc/terms/",
+	"foaf":   "http://xmlns.com/foaf/0.1/",
+	"og":     "http://ogp.me/ns#",
+	"rdf":    "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+	"rdfs":   "http://www.w3.org/2000/01/rdf-schema#",
+	"schema": "http://schema.org/",
+	"xsd":    "http://www.w3.org/2001/XMLSchema#",
+}
+
+// RDFaTriple is a statement of the page. Subjects are IRIs or blank nodes
+// such as "_:b0", objects are IRIs, blank nodes or literal values.
+type RDFaTriple struct {
+	Subject   string
+	Predicate string
+	Object    string
+}
+
+// RDFa returns the triples described by the RDFa Lite attributes of the
+// page : vocab, prefix, typeof, property and resource.
+func (resource *WebResource) RDFa() ([]RDFaTriple, error) {
+	doc, err := resource.document()
+
+	if err != nil {
+		return make([]RDFaTriple, 0), err
+	}
+
+	parser := &rdfaParser{resource: resource, base: resource.baseUrl(doc), triples: make([]RDFaTriple, 0)}
+	context := rdfaContext{subject: resource.url.String(), prefixes: RDFaPrefixes}
+	for child := doc.FirstChild; child != nil; child = child.NextSibling {
+		parser.walk(child, context)
+	}
+
+	return parser.triples, nil
+}
+
+type rdfaContext struct {
+	subject  string
+	vocab    string
+	prefixes map[string]string
+}
+
+type rdfaParser struct {
+	resource   *WebResource
+	base       *url.URL
+	triples    []RDFaTriple
+	blankNodes int
+}
+
+func (parser *rdfaParser) walk(n *html.Node, context rdfaContext) {
+	if n.Type != html.ElementNode {
+		return
+	}
+
+	if vocab, ok := nodeAttr(n, "vocab"); ok {
+		context.vocab = vocab
+	}
+	if prefix, ok := nodeAttr(n, "prefix"); ok {
+		context.prefixes = parseRDFaPrefixes(prefix, context.prefixes)
+	}
+
+	properties := strings.Fields(htmlquery.SelectAttr(n, "property"))
+	types := strings.Fields(htmlquery.SelectAttr(n, "typeof"))
+	_, hasTypeof := nodeAttr(n, "typeof")
+
+	if hasTypeof {
+		subject := parser.newSubject(n)
+		for _, property := range properties {
+			parser.add(context.subject, parser.expand(property, context), subject)
+		}
+		for _, typeName := range types {
+			parser.add(subject, RDFTypeIRI, parser.expand(typeName, context))
+		}
+
+		context.subject = subject
+	} else {
+		if len(properties) > 0 {
+			object := parser.object(n)
+			for _, property := range properties {
+				parser.add(context.subject, parser.expand(property, context), object)
+			}
+		}
+
+		if resource, ok := nodeAttr(n, "resource"); ok && len(properties) == 0 {
+			// A resource without property changes the subject of the descendants
+			context.subject = parser.resolve(resource)
+		}
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		parser.walk(child, context)
+	}
+}
+
+// newSubject returns the subject of an element with a typeof attribute.
+func (parser *rdfaParser) newSubject(n *html.Node) string {
+	if resource, ok := nodeAttr(n, "resource"); ok {
+		return parser.resolve(resource)
+	}
+
+	parser.blankNodes++
+	return fmt.Sprintf("_:b%d", parser.blankNodes-1)
+}
+
+// object returns the value of an element with a property attribute.
+func (parser *rdfaParser) object(n *html.Node) string {
+	if content, ok := nodeAttr(n, "content"); ok {
+		return content
+	}
+
+	for _, attribute := range []string{"resource", "href", "src"} {
+		if value, ok := nodeAttr(n, attribute); ok {
+			return parser.resolve(value)
+		}
+	}
+
+	if n.Data == "time" {
+		if datetime, ok := nodeAttr(n, "datetime"); ok {
+			return datetime
+		}
+	}
+
+	return strings.TrimSpace(htmlquery.InnerText(n))
+}
+
+// expand turns a term or a compact IRI into an IRI.
+func (parser *rdfaParser) expand(term string, context rdfaContext) string {
+	if index := strings.Index(term, ":"); index > 0 {
+		if namespace, ok := context.prefixes[term[:index]]; ok {
+			return namespace + term[index+1:]
+		}
+
+		return term
+	}
+
+	return context.vocab + term
+}
+
+func (parser *rdfaParser) resolve(rawUrl string) string {
+	if strings.HasPrefix(rawUrl, "_:") {
+		return rawUrl
+	}
+
+	resolvedUrl, err := parser.resource.resolveUrl(rawUrl, parser.base)
+	if err != nil {
+		return rawUrl
+	}
+
+	return resolvedUrl
+}
+
+func (parser *rdfaParser) add(subject string, predicate string, object string) {
+	parser.triples = append(parser.triples, RDFaTriple{Subject: subject, Predicate: predicate, Object: object})
+}
+
+// parseRDFaPrefixes returns prefixes along with the "name: iri" pairs of a
+// prefix attribute.
+func parseRDFaPrefixes(attribute string, prefixes map[string]string) map[string]string {
+	merged := make(map[string]string, len(prefixes))
+	for name, namespace := range prefixes {
+		merged[name] = namespace
+	}
+
+	fields := strings.Fields(attribute)
+	for index := 0; index+1 < len(fields); index += 2 {
+		if strings.HasSuffix(fields[index], ":") {
+			merged[strings.ToLower(strings.TrimSuffix(fields[index], ":"))] = fields[index+1]
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"net/http"
+	"time"
+)
+
+// RedirectHop is a response that redirected the request elsewhere.
+type RedirectHop struct {
+	URL        string
+	StatusCode int
+}
+
+// ResponseMetadata describes the HTTP exchange a WebResource was fetched
+// with.
+type ResponseMetadata struct {
+	// RequestURL is the url that was asked for, before any redirection.
+	RequestURL string
+	StatusCode int
+	Status     string
+	Proto      string
+	Header     http.Header
+	// FetchedAt is the time the request was sent.
+	FetchedAt time.Time
+	// Duration goes from sending the request to reading the whole body.
+	Duration time.Duration
+	// ContentLength is the announced length of the body, or the length
+	// actually read when the server did not announce one.
+	ContentLength int64
+	// Redirects lists the redirections followed from RequestURL, in order.
+	Redirects []RedirectHop
+}
+
+func (resource WebResource) ResponseMetadata() ResponseMetadata {
+	return resource.responseMetadata
+}
+
+func (resource WebResource) RequestURL() string {
+	return resource.responseMetadata.RequestURL
+}
+
+// FinalURL is the url the resource was served from once redirections were
+// followed. Relative links of the resource are resolved against it.
+func (resource WebResource) FinalURL() string {
+	return resource.url.String()
+}
+
+func (resource WebResource) Redirects() []RedirectHop {
+	return resource.responseMetadata.Redirects
+}
+
+func (resource WebResource) StatusCode() int {
+	return resource.responseMetadata.StatusCode
+}
+
+// Header returns the response headers. It is never nil.
+func (resource WebResource) Header() http.Header {
+	if resource.responseMetadata.Header == nil {
+		return http.Header{}
+	}
+
+	return resource.responseMetadata.Header
+}
+
+// Cookies parses the Set-Cookie headers of the response.
+func (resource WebResource) Cookies() []*http.Cookie {
+	response := http.Response{Header: resource.Header()}
+	return response.Cookies()
+}
+
+func (resource WebResource) FetchedAt() time.Time {
+	return resource.responseMetadata.FetchedAt
+}
+
+func (resource WebResource) DownloadDuration() time.Duration {
+	return resource.responseMetadata.Duration
+}
+
+func (resource WebResource) ContentLength() int64 {
+	return resource.responseMetadata.ContentLength
+}
+
+// ChangeResponseMetadata stores a copy of metadata on the resource.
+func (resource *WebResource) ChangeResponseMetadata(metadata ResponseMetadata) {
+	metadata.Header = metadata.Header.Clone()
+	metadata.Redirects = append([]RedirectHop(nil), metadata.Redirects...)
+	resource.responseMetadata = metadata
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDefaultResponseMetadata(t *testing.T) {
+	webResource, err := NewWebResource("https://example.com/home", "text/html", []byte("Hello, World!"))
+
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+
+	if webResource.RequestURL() != "https://example.com/home" {
+		t.Logf("Wrong request url %s", webResource.RequestURL())
+		t.Fail()
+	}
+
+	if webResource.ContentLength() != 13 {
+		t.Logf("Wrong content length %d", webResource.ContentLength())
+		t.Fail()
+	}
+
+	if webResource.Header() == nil || webResource.StatusCode() != 0 {
+		t.Log("Wrong default metadata")
+		t.Fail()
+	}
+}
+
+func TestChangeResponseMetadata(t *testing.T) {
+	webResource, err := NewWebResource("https://example.com/home", "text/html", []byte("Hello, World!"))
+
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+
+	header := make(http.Header)
+	header.Set("ETag", `"abc"`)
+	header.Add("Set-Cookie", "session=123; Path=/")
+	fetchedAt := time.Date(2020, time.December, 1, 10, 0, 0, 0, time.UTC)
+
+	webResource.ChangeResponseMetadata(ResponseMetadata{
+		RequestURL:    "http://example.com/",
+		StatusCode:    200,
+		Status:        "200 OK",
+		Proto:         "HTTP/1.1",
+		Header:        header,
+		FetchedAt:     fetchedAt,
+		Duration:      time.Second,
+		ContentLength: 13,
+	})
+
+	// The resource keeps its own copy of the headers
+	header.Set("ETag", `"modified"`)
+
+	if webResource.Header().Get("ETag") != `"abc"` {
+		t.Logf("Wrong ETag %s", webResource.Header().Get("ETag"))
+		t.Fail()
+	}
+
+	if webResource.StatusCode() != 200 || webResource.ResponseMetadata().Proto != "HTTP/1.1" {
+		t.Log("Wrong status")
+		t.Fail()
+	}
+
+	if !webResource.FetchedAt().Equal(fetchedAt) || webResource.DownloadDuration() != time.Second {
+		t.Log("Wrong timing")
+		t.Fail()
+	}
+
+	cookies := webResource.Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "session" || cookies[0].Value != "123" {
+		t.Logf("Wrong cookies %v", cookies)
+		t.Fail()
+	}
+
+	if webResource.RequestURL() != "http://example.com/" {
+		t.Logf("Wrong request url %s", webResource.RequestURL())
+		t.Fail()
+	}
+}
@@ -0,0 +1,358 @@
+package domain
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Product is a schema.org Product found in the JSON-LD or the Microdata of a
+// page.
+type Product struct {
+	Name        string
+	Description string
+	SKU         string
+	Brand       string
+	URL         string
+	Images      []string
+	Offers      []Offer
+	Rating      *AggregateRating
+}
+
+// Offer is a schema.org Offer of a Product. The price of an AggregateOffer
+// is its lowPrice.
+type Offer struct {
+	Price         string
+	PriceCurrency string
+	Availability  string
+	URL           string
+	Seller        string
+}
+
+type AggregateRating struct {
+	RatingValue string
+	BestRating  string
+	ReviewCount string
+}
+
+// Article is a schema.org Article or one of its sub types, such as
+// NewsArticle or BlogPosting, given by Type.
+type Article struct {
+	Type          string
+	Headline      string
+	Description   string
+	Authors       []string
+	Publisher     string
+	DatePublished string
+	DateModified  string
+	URL           string
+	Images        []string
+}
+
+// BreadcrumbList is a schema.org BreadcrumbList, items sorted by position.
+type BreadcrumbList struct {
+	Items []BreadcrumbItem
+}
+
+type BreadcrumbItem struct {
+	Position int
+	Name     string
+	URL      string
+}
+
+// ArticleTypes are the schema.org types returned by Articles.
+var ArticleTypes = []string{"Article", "NewsArticle", "BlogPosting", "TechArticle", "ScholarlyArticle", "Report", "SocialMediaPosting", "LiveBlogPosting"}
+
+// Products returns the schema.org products of the page.
+func (resource *WebResource) Products() ([]Product, error) {
+	products := make([]Product, 0)
+	objects, err := resource.schemaObjects("Product", "IndividualProduct", "ProductModel")
+
+	for _, object := range objects {
+		product := Product{
+			Name:        schemaText(object["name"]),
+			Description: schemaText(object["description"]),
+			SKU:         schemaText(object["sku"]),
+			Brand:       schemaText(object["brand"]),
+			URL:         schemaUrl(object["url"]),
+			Images:      schemaUrls(object["image"]),
+			Offers:      make([]Offer, 0),
+		}
+
+		for _, offer := range schemaObjectsOf(object["offers"]) {
+			price := schemaText(offer["price"])
+			if price == "" {
+				price = schemaText(offer["lowPrice"])
+			}
+
+			product.Offers = append(product.Offers, Offer{
+				Price:         price,
+				PriceCurrency: schemaText(offer["priceCurrency"]),
+				Availability:  schemaText(offer["availability"]),
+				URL:           schemaUrl(offer["url"]),
+				Seller:        schemaText(offer["seller"]),
+			})
+		}
+
+		if ratings := schemaObjectsOf(object["aggregateRating"]); len(ratings) > 0 {
+			reviewCount := schemaText(ratings[0]["reviewCount"])
+			if reviewCount == "" {
+				reviewCount = schemaText(ratings[0]["ratingCount"])
+			}
+
+			product.Rating = &AggregateRating{
+				RatingValue: schemaText(ratings[0]["ratingValue"]),
+				BestRating:  schemaText(ratings[0]["bestRating"]),
+				ReviewCount: reviewCount,
+			}
+		}
+
+		products = append(products, product)
+	}
+
+	return products, err
+}
+
+// Articles returns the schema.org articles of the page, see ArticleTypes.
+func (resource *WebResource) Articles() ([]Article, error) {
+	articles := make([]Article, 0)
+	objects, err := resource.schemaObjects(ArticleTypes...)
+
+	for _, object := range objects {
+		articles = append(articles, Article{
+			Type:          schemaTypeOf(object, ArticleTypes),
+			Headline:      schemaText(object["headline"]),
+			Description:   schemaText(object["description"]),
+			Authors:       schemaTexts(object["author"]),
+			Publisher:     schemaText(object["publisher"]),
+			DatePublished: schemaText(object["datePublished"]),
+			DateModified:  schemaText(object["dateModified"]),
+			URL:           schemaUrl(object["url"]),
+			Images:        schemaUrls(object["image"]),
+		})
+	}
+
+	return articles, err
+}
+
+// Breadcrumbs returns the schema.org breadcrumb lists of the page.
+func (resource *WebResource) Breadcrumbs() ([]BreadcrumbList, error) {
+	breadcrumbs := make([]BreadcrumbList, 0)
+	objects, err := resource.schemaObjects("BreadcrumbList")
+
+	for _, object := range objects {
+		breadcrumb := BreadcrumbList{Items: make([]BreadcrumbItem, 0)}
+
+		for _, element := range schemaObjectsOf(object["itemListElement"]) {
+			position, _ := strconv.Atoi(schemaText(element["position"]))
+			item := BreadcrumbItem{
+				Position: position,
+				Name:     schemaText(element["name"]),
+				URL:      schemaUrl(element["item"]),
+			}
+
+			// The name is often given by the item itself
+			if targets := schemaObjectsOf(element["item"]); item.Name == "" && len(targets) > 0 {
+				item.Name = schemaText(targets[0]["name"])
+			}
+
+			breadcrumb.Items = append(breadcrumb.Items, item)
+		}
+
+		sort.SliceStable(breadcrumb.Items, func(i, j int) bool {
+			return breadcrumb.Items[i].Position < breadcrumb.Items[j].Position
+		})
+
+		breadcrumbs = append(breadcrumbs, breadcrumb)
+	}
+
+	return breadcrumbs, err
+}
+
+// schemaObjects returns the JSON-LD and Microdata objects of the page having
+// one of the given schema.org types, wherever they are nested.
+func (resource *WebResource) schemaObjects(types ...string) ([]map[string]interface{}, error) {
+	jsonLD, err := resource.JSONLD()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := resource.Microdata()
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make([]interface{}, 0, len(jsonLD)+len(items))
+	for _, object := range jsonLD {
+		roots = append(roots, object)
+	}
+	for _, item := range items {
+		roots = append(roots, microdataToObject(item))
+	}
+
+	matches := make([]map[string]interface{}, 0)
+	findSchemaObjects(roots, types, &matches)
+
+	return matches, nil
+}
+
+func findSchemaObjects(value interface{}, types []string, matches *[]map[string]interface{}) {
+	switch value := value.(type) {
+	case []interface{}:
+		for _, item := range value {
+			findSchemaObjects(item, types, matches)
+		}
+	case map[string]interface{}:
+		if schemaTypeOf(value, types) != "" {
+			*matches = append(*matches, value)
+			return
+		}
+
+		// Visit keys in a fixed order, map iteration is random
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			if key != "@context" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			findSchemaObjects(value[key], types, matches)
+		}
+	}
+}
+
+// microdataToObject converts an item to the shape of a JSON-LD object.
+func microdataToObject(item *MicrodataItem) map[string]interface{} {
+	types := make([]interface{}, 0, len(item.Types))
+	for _, itemType := range item.Types {
+		types = append(types, itemType)
+	}
+
+	object := map[string]interface{}{"@type": types}
+	if item.ID != "" {
+		object["@id"] = item.ID
+	}
+
+	for name, values := range item.Properties {
+		converted := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			if nested, ok := value.(*MicrodataItem); ok {
+				converted = append(converted, microdataToObject(nested))
+			} else {
+				converted = append(converted, value)
+			}
+		}
+		object[name] = converted
+	}
+
+	return object
+}
+
+// schemaTypeOf returns the first type of object among types, or an empty
+// string. Full IRIs such as https://schema.org/Product match Product.
+func schemaTypeOf(object map[string]interface{}, types []string) string {
+	for _, value := range schemaTexts(object["@type"]) {
+		name := value[strings.LastIndexAny(value, "/#:")+1:]
+		for _, schemaType := range types {
+			if name == schemaType {
+				return schemaType
+			}
+		}
+	}
+
+	return ""
+}
+
+// schemaText returns a value as text. Objects are represented by their
+// @value, name, url or @id and lists by their first non-empty value.
+func schemaText(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	case map[string]interface{}:
+		for _, key := range []string{"@value", "name", "url", "@id"} {
+			if text := schemaText(value[key]); text != "" {
+				return text
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if text := schemaText(item); text != "" {
+				return text
+			}
+		}
+	}
+
+	return ""
+}
+
+func schemaTexts(value interface{}) []string {
+	texts := make([]string, 0)
+
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+
+	for _, item := range items {
+		if text := schemaText(item); text != "" {
+			texts = append(texts, text)
+		}
+	}
+
+	return texts
+}
+
+// schemaUrl returns a value as an url, objects such as ImageObject being
+// represented by their url.
+func schemaUrl(value interface{}) string {
+	urls := schemaUrls(value)
+	if len(urls) == 0 {
+		return ""
+	}
+
+	return urls[0]
+}
+
+func schemaUrls(value interface{}) []string {
+	urls := make([]string, 0)
+
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+
+	for _, item := range items {
+		text := schemaText(item)
+		if object, ok := item.(map[string]interface{}); ok {
+			text = schemaText([]interface{}{object["url"], object["contentUrl"], object["@id"]})
+		}
+
+		if text != "" {
+			urls = append(urls, text)
+		}
+	}
+
+	return urls
+}
+
+func schemaObjectsOf(value interface{}) []map[string]interface{} {
+	objects := make([]map[string]interface{}, 0)
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		objects = append(objects, value)
+	case []interface{}:
+		for _, item := range value {
+			objects = append(objects, schemaObjectsOf(item)...)
+		}
+	}
+
+	return objects
+}
@@ -0,0 +1,195 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestProducts(t *testing.T) {
+	strContent := `
+	<html>
+		<head>
+			<script type="application/ld+json">
+			{
+				"@context": "https://schema.org",
+				"@type": "WebPage",
+				"mainEntity": {
+					"@type": "Product",
+					"name": "Gopher plush",
+					"sku": "GO-1",
+					"brand": {"@type": "Brand", "name": "Go"},
+					"image": ["https://example.com/1.jpg", {"@type": "ImageObject", "url": "https://example.com/2.jpg"}],
+					"offers": {"@type": "AggregateOffer", "lowPrice": 12.5, "priceCurrency": "EUR"},
+					"aggregateRating": {"@type": "AggregateRating", "ratingValue": "4.5", "ratingCount": 31}
+				}
+			}
+			</script>
+		</head>
+		<body>
+			<div itemscope itemtype="http://schema.org/Product">
+				<span itemprop="name">Sticker pack</span>
+				<div itemprop="offers" itemscope itemtype="http://schema.org/Offer">
+					<meta itemprop="price" content="3.00">
+					<link itemprop="availability" href="https://schema.org/InStock">
+				</div>
+			</div>
+		</body>
+	</html>
+	`
+	webResource := makeStructuredDataWebResource(t, strContent)
+
+	products, err := webResource.Products()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if len(products) != 2 {
+		t.Logf("Expected 2 products, got %d", len(products))
+		t.FailNow()
+	}
+
+	plush := products[0]
+	if plush.Name != "Gopher plush" || plush.SKU != "GO-1" || plush.Brand != "Go" {
+		t.Logf("Wrong product %+v", plush)
+		t.Fail()
+	}
+
+	expected := []string{"https://example.com/1.jpg", "https://example.com/2.jpg"}
+	if !textsMatch(expected, plush.Images) {
+		t.Logf("Images %s different of %s", plush.Images, expected)
+		t.Fail()
+	}
+
+	if len(plush.Offers) != 1 || plush.Offers[0].Price != "12.5" || plush.Offers[0].PriceCurrency != "EUR" {
+		t.Logf("Wrong offers %+v", plush.Offers)
+		t.Fail()
+	}
+
+	if plush.Rating == nil || plush.Rating.RatingValue != "4.5" || plush.Rating.ReviewCount != "31" {
+		t.Logf("Wrong rating %+v", plush.Rating)
+		t.Fail()
+	}
+
+	stickers := products[1]
+	if stickers.Name != "Sticker pack" || len(stickers.Offers) != 1 || stickers.Offers[0].Price != "3.00" || stickers.Offers[0].Availability != "https://schema.org/InStock" {
+		t.Logf("Wrong microdata product %+v", stickers)
+		t.Fail()
+	}
+}
+
+func TestArticles(t *testing.T) {
+	strContent := `
+	<script type="application/ld+json">
+	{
+		"@context": "https://schema.org",
+		"@type": "NewsArticle",
+		"headline": "Crawling the web",
+		"author": [{"@type": "Person", "name": "Alice"}, {"@type": "Person", "name": "Bob"}],
+		"publisher": {"@type": "Organization", "name": "Example news"},
+		"datePublished": "2020-05-01T08:00:00+02:00",
+		"image": "https://example.com/cover.jpg"
+	}
+	</script>
+	`
+	webResource := makeStructuredDataWebResource(t, strContent)
+
+	articles, err := webResource.Articles()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if len(articles) != 1 {
+		t.Logf("Expected 1 article, got %d", len(articles))
+		t.FailNow()
+	}
+
+	article := articles[0]
+	if article.Type != "NewsArticle" || article.Headline != "Crawling the web" || article.Publisher != "Example news" || article.DatePublished != "2020-05-01T08:00:00+02:00" {
+		t.Logf("Wrong article %+v", article)
+		t.Fail()
+	}
+
+	if !textsMatch([]string{"Alice", "Bob"}, article.Authors) || !textsMatch([]string{"https://example.com/cover.jpg"}, article.Images) {
+		t.Logf("Wrong authors %s or images %s", article.Authors, article.Images)
+		t.Fail()
+	}
+}
+
+func TestArticlesNestedOrder(t *testing.T) {
+	strContent := `
+	<script type="application/ld+json">
+	{
+		"@context": "https://schema.org",
+		"@type": "WebPage",
+		"mainEntity": {"@type": "Article", "headline": "Main"},
+		"hasPart": {"@type": "Article", "headline": "Part"},
+		"about": {"@type": "Article", "headline": "About"}
+	}
+	</script>
+	`
+	webResource := makeStructuredDataWebResource(t, strContent)
+
+	for i := 0; i < 10; i++ {
+		articles, err := webResource.Articles()
+
+		if err != nil || len(articles) != 3 {
+			t.Logf("Expected 3 articles, got %v, %v", articles, err)
+			t.FailNow()
+		}
+
+		if articles[0].Headline != "About" || articles[1].Headline != "Part" || articles[2].Headline != "Main" {
+			t.Logf("Wrong order %+v", articles)
+			t.FailNow()
+		}
+	}
+}
+
+func TestBreadcrumbs(t *testing.T) {
+	strContent := `
+	<script type="application/ld+json">
+	{
+		"@context": "https://schema.org",
+		"@type": "BreadcrumbList",
+		"itemListElement": [
+			{"@type": "ListItem", "position": 2, "item": {"@id": "https://example.com/books", "name": "Books"}},
+			{"@type": "ListItem", "position": 1, "name": "Home", "item": "https://example.com/"},
+			{"@type": "ListItem", "position": 3, "name": "Go"}
+		]
+	}
+	</script>
+	`
+	webResource := makeStructuredDataWebResource(t, strContent)
+
+	breadcrumbs, err := webResource.Breadcrumbs()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if len(breadcrumbs) != 1 {
+		t.Logf("Expected 1 breadcrumb list, got %d", len(breadcrumbs))
+		t.FailNow()
+	}
+
+	expected := []BreadcrumbItem{
+		{1, "Home", "https://example.com/"},
+		{2, "Books", "https://example.com/books"},
+		{3, "Go", ""},
+	}
+	items := breadcrumbs[0].Items
+	if len(items) != len(expected) {
+		t.Logf("Items %+v different of %+v", items, expected)
+		t.FailNow()
+	}
+
+	for index, item := range items {
+		if item != expected[index] {
+			t.Logf("Item %+v different of %+v", item, expected[index])
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// Selection is an ordered list of the HTML nodes matched by a XPath or CSS
+// query. Selections can be queried again to select nodes relative to the
+// ones they hold.
+// A failing query returns an empty Selection carrying the error, which is
+// passed along by further queries and reported by Err.
+type Selection struct {
+	nodes []*html.Node
+	err   error
+}
+
+// Select returns the nodes of the page matching the XPath expression expr.
+// Attributes and text nodes can be selected too, their value being
+// available through Text.
+func (resource *WebResource) Select(expr string) Selection {
+	doc, err := resource.document()
+
+	if err != nil {
+		return Selection{err: err}
+	}
+
+	return NewSelection(doc).Select(expr)
+}
+
+// SelectCSS returns the elements of the page matching the CSS selector.
+// See CSSToXPath for the supported syntax.
+func (resource *WebResource) SelectCSS(selector string) Selection {
+	doc, err := resource.document()
+
+	if err != nil {
+		return Selection{err: err}
+	}
+
+	return NewSelection(doc).SelectCSS(selector)
+}
+
+// NewSelection wraps already parsed nodes into a Selection.
+func NewSelection(nodes ...*html.Node) Selection {
+	return Selection{nodes: nodes}
+}
+
+// Select returns the nodes matching the XPath expression expr relative to
+// each node of the selection, without duplicates.
+func (selection Selection) Select(expr string) Selection {
+	if selection.err != nil {
+		return selection
+	}
+
+	nodes := make([]*html.Node, 0)
+	seen := make(map[*html.Node]bool)
+	for _, node := range selection.nodes {
+		matches, err := htmlquery.QueryAll(node, expr)
+
+		if err != nil {
+			return Selection{err: err}
+		}
+
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				nodes = append(nodes, match)
+			}
+		}
+	}
+
+	return Selection{nodes: nodes}
+}
+
+// SelectCSS returns the elements matching the CSS selector among the
+// descendants of each node of the selection, without duplicates.
+func (selection Selection) SelectCSS(selector string) Selection {
+	if selection.err != nil {
+		return selection
+	}
+
+	expr, err := CSSToXPath(selector)
+
+	if err != nil {
+		return Selection{err: err}
+	}
+
+	return selection.Select(expr)
+}
+
+// Err returns the error of the query which built the selection, if any.
+func (selection Selection) Err() error {
+	return selection.err
+}
+
+func (selection Selection) Len() int {
+	return len(selection.nodes)
+}
+
+func (selection Selection) Nodes() []*html.Node {
+	return selection.nodes
+}
+
+// First returns a selection holding the first node only.
+func (selection Selection) First() Selection {
+	return selection.At(0)
+}
+
+// At returns a selection holding the node at index only. It is empty when
+// index is out of range.
+func (selection Selection) At(index int) Selection {
+	if selection.err != nil || index < 0 || index >= len(selection.nodes) {
+		return Selection{err: selection.err}
+	}
+
+	return NewSelection(selection.nodes[index])
+}
+
+// Each calls f with a selection holding each node in turn.
+func (selection Selection) Each(f func(index int, node Selection)) {
+	for index, node := range selection.nodes {
+		f(index, NewSelection(node))
+	}
+}
+
+// Text returns the text content of the first node, with surrounding spaces
+// trimmed, or an empty string if the selection is empty.
+func (selection Selection) Text() string {
+	if len(selection.nodes) == 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(htmlquery.InnerText(selection.nodes[0]))
+}
+
+// Texts returns the trimmed text content of every node.
+func (selection Selection) Texts() []string {
+	texts := make([]string, 0, len(selection.nodes))
+	for _, node := range selection.nodes {
+		texts = append(texts, strings.TrimSpace(htmlquery.InnerText(node)))
+	}
+
+	return texts
+}
+
+// Attr returns the value of the attribute name of the first node having it.
+// The boolean is false when no node has the attribute.
+func (selection Selection) Attr(name string) (string, bool) {
+	for _, node := range selection.nodes {
+		if value, ok := nodeAttr(node, name); ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// Attrs returns the value of the attribute name of every node having it.
+func (selection Selection) Attrs(name string) []string {
+	values := make([]string, 0, len(selection.nodes))
+	for _, node := range selection.nodes {
+		if value, ok := nodeAttr(node, name); ok {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// HTML returns the markup of the first node, tags included, or an empty
+// string if the selection is empty.
+func (selection Selection) HTML() string {
+	if len(selection.nodes) == 0 {
+		return ""
+	}
+
+	return htmlquery.OutputHTML(selection.nodes[0], true)
+}
+
+func nodeAttr(node *html.Node, name string) (string, bool) {
+	for _, attribute := range node.Attr {
+		if attribute.Key == name {
+			return attribute.Val, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"testing"
+)
+
+const selectionContent = `
+<html>
+	<body>
+		<div id="products">
+			<div class="product featured">
+				<h2>Gopher plush</h2>
+				<span class="price">12.50</span>
+				<a href="/gopher">Details</a>
+			</div>
+			<div class="product">
+				<h2>Sticker <em>pack</em></h2>
+				<span class="price">3.00</span>
+				<a href="/stickers">Details</a>
+			</div>
+		</div>
+	</body>
+</html>
+`
+
+func TestSelect(t *testing.T) {
+	webResource := makeSelectionWebResource(t)
+
+	titles := webResource.Select("//div[@class]/h2")
+
+	if titles.Err() != nil {
+		t.Log(titles.Err())
+		t.FailNow()
+	}
+
+	expected := []string{"Gopher plush", "Sticker pack"}
+	if !textsMatch(expected, titles.Texts()) {
+		t.Logf("Selected texts %s does not match expected ones %s", titles.Texts(), expected)
+		t.Fail()
+	}
+
+	if titles.Text() != "Gopher plush" {
+		t.Logf("Text should be the one of the first node, got %s", titles.Text())
+		t.Fail()
+	}
+
+	hrefs := webResource.Select("//a/@href").Texts()
+	expected = []string{"/gopher", "/stickers"}
+	if !textsMatch(expected, hrefs) {
+		t.Logf("Selected attributes %s does not match expected ones %s", hrefs, expected)
+		t.Fail()
+	}
+}
+
+func TestSelectCSS(t *testing.T) {
+	webResource := makeSelectionWebResource(t)
+
+	featured := webResource.SelectCSS("#products > .product.featured")
+
+	if featured.Len() != 1 {
+		t.Logf("Expected 1 featured product, got %d", featured.Len())
+		t.FailNow()
+	}
+
+	if price := featured.SelectCSS("span.price").Text(); price != "12.50" {
+		t.Logf("Price %s different of 12.50", price)
+		t.Fail()
+	}
+
+	if html := featured.SelectCSS("h2").HTML(); html != "<h2>Gopher plush</h2>" {
+		t.Logf("Unexpected html %s", html)
+		t.Fail()
+	}
+
+	href, ok := webResource.SelectCSS("a").Attr("href")
+	if !ok || href != "/gopher" {
+		t.Logf("Attr should return the first href, got %s", href)
+		t.Fail()
+	}
+
+	if _, ok := featured.Attr("data-missing"); ok {
+		t.Log("Missing attribute should not be found")
+		t.Fail()
+	}
+
+	expected := []string{"/gopher", "/stickers"}
+	if hrefs := webResource.SelectCSS("a").Attrs("href"); !textsMatch(expected, hrefs) {
+		t.Logf("Selected attributes %s does not match expected ones %s", hrefs, expected)
+		t.Fail()
+	}
+}
+
+func TestSelectionChaining(t *testing.T) {
+	webResource := makeSelectionWebResource(t)
+
+	products := webResource.SelectCSS(".product")
+	prices := make([]string, 0)
+	products.Each(func(index int, product Selection) {
+		prices = append(prices, product.Select(".//span[@class='price']").Text())
+	})
+
+	expected := []string{"12.50", "3.00"}
+	if !textsMatch(expected, prices) {
+		t.Logf("Prices %s does not match expected ones %s", prices, expected)
+		t.Fail()
+	}
+
+	if title := products.At(1).SelectCSS("h2 em").Text(); title != "pack" {
+		t.Logf("Title %s different of pack", title)
+		t.Fail()
+	}
+
+	if products.At(5).Len() != 0 || products.At(5).Text() != "" {
+		t.Log("Out of range selection should be empty")
+		t.Fail()
+	}
+
+	// Sub-selections of several nodes do not hold duplicates
+	if divs := webResource.SelectCSS("div").SelectCSS("h2"); divs.Len() != 2 {
+		t.Logf("Expected 2 titles, got %d", divs.Len())
+		t.Fail()
+	}
+}
+
+func TestSelectCSSRelative(t *testing.T) {
+	content := `<ul id="menu"><li>1<ul><li>nested</li></ul></li><li>2</li><li>3</li><li>4</li><li>5</li></ul>`
+	webResource, _ := NewWebResource("https://example.com/", "text/html", []byte(content))
+	menu := webResource.SelectCSS("#menu")
+
+	if items := menu.SelectCSS("> li"); items.Len() != 5 {
+		t.Logf("Expected the 5 children of the menu, got %d", items.Len())
+		t.Fail()
+	}
+
+	cases := []struct {
+		selector string
+		expected []string
+	}{
+		{"> li:nth-child(2n+1)", []string{"1nested", "3", "5"}},
+		{"> li:nth-child(even)", []string{"2", "4"}},
+		{"> li:nth-child(-n+2)", []string{"1nested", "2"}},
+		{"> li:nth-child(3n)", []string{"3"}},
+	}
+
+	for _, c := range cases {
+		texts := menu.SelectCSS(c.selector).Texts()
+		if !textsMatch(c.expected, texts) {
+			t.Logf("%s : texts %s different of %s", c.selector, texts, c.expected)
+			t.Fail()
+		}
+	}
+
+	if next := menu.SelectCSS("li:nth-child(3)").SelectCSS("+ li").Text(); next != "4" {
+		t.Logf("Next sibling %s different of 4", next)
+		t.Fail()
+	}
+}
+
+func TestSelectionInvalidQuery(t *testing.T) {
+	webResource := makeSelectionWebResource(t)
+
+	selection := webResource.Select("//div[")
+	if selection.Err() == nil || selection.Len() != 0 {
+		t.Log("Invalid XPath should give an empty selection with an error")
+		t.Fail()
+	}
+
+	selection = webResource.SelectCSS("div:hover").SelectCSS("a")
+	if selection.Err() == nil {
+		t.Log("Error of an invalid selector should be kept by sub-selections")
+		t.Fail()
+	}
+}
+
+func makeSelectionWebResource(t *testing.T) *WebResource {
+	webResource, err := NewWebResource("https://example.com/shop", "text/html", []byte(selectionContent))
+
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+
+	return webResource
+}
+
+func textsMatch(expected []string, received []string) bool {
+	if len(expected) != len(received) {
+		return false
+	}
+
+	for index, expect := range expected {
+		if expect != received[index] {
+			return false
+		}
+	}
+	return true
+}
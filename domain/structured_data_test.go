@@ -0,0 +1,212 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestJSONLD(t *testing.T) {
+	strContent := `
+	<html>
+		<head>
+			<script type="application/ld+json">{"@context": "https://schema.org", "@type": "Organization", "name": "Example"}</script>
+			<script type="application/ld+json">[{"@type": "WebSite"}, {"@type": "WebPage"}]</script>
+			<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [{"@type": "Person"}]}</script>
+			<script type="application/ld+json">{ not json </script>
+			<script type="text/javascript">{"@type": "Ignored"}</script>
+		</head>
+	</html>
+	`
+	webResource := makeStructuredDataWebResource(t, strContent)
+
+	objects, err := webResource.JSONLD()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	types := make([]string, 0)
+	for _, object := range objects {
+		types = append(types, schemaText(object["@type"]))
+	}
+
+	expected := []string{"Organization", "WebSite", "WebPage", "Person"}
+	if !textsMatch(expected, types) {
+		t.Logf("Types %s different of %s", types, expected)
+		t.FailNow()
+	}
+
+	if objects[3]["@context"] != "https://schema.org" {
+		t.Log("Objects of a graph should get the context of the graph")
+		t.Fail()
+	}
+}
+
+func TestMicrodata(t *testing.T) {
+	strContent := `
+	<html>
+		<body>
+			<div itemscope itemtype="https://schema.org/Movie" itemref="director">
+				<h1 itemprop="name">Avatar</h1>
+				<img itemprop="image" src="/avatar.jpg">
+				<time itemprop="datePublished" datetime="2009-12-18">December 2009</time>
+				<div itemprop="aggregateRating" itemscope itemtype="https://schema.org/AggregateRating">
+					<meta itemprop="ratingValue" content="8">
+					<span itemprop="name">Should not be the name of the movie</span>
+				</div>
+				<span itemprop="genre keywords">Science fiction</span>
+			</div>
+			<p id="director">Directed by <span itemprop="director">James Cameron</span></p>
+		</body>
+	</html>
+	`
+	webResource := makeStructuredDataWebResource(t, strContent)
+
+	items, err := webResource.Microdata()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if len(items) != 1 {
+		t.Logf("Expected 1 top-level item, got %d", len(items))
+		t.FailNow()
+	}
+
+	movie := items[0]
+	expected := map[string]string{
+		"name":          "Avatar",
+		"image":         "https://example.com/avatar.jpg",
+		"datePublished": "2009-12-18",
+		"genre":         "Science fiction",
+		"keywords":      "Science fiction",
+		"director":      "James Cameron",
+	}
+	for name, value := range expected {
+		if movie.Property(name) != value {
+			t.Logf("Property %s is %q instead of %q", name, movie.Property(name), value)
+			t.Fail()
+		}
+	}
+
+	if len(movie.Properties["name"]) != 1 {
+		t.Log("Properties of nested items should not belong to the parent")
+		t.Fail()
+	}
+
+	rating := movie.Item("aggregateRating")
+	if rating == nil || rating.Property("ratingValue") != "8" || rating.Types[0] != "https://schema.org/AggregateRating" {
+		t.Logf("Wrong nested item %+v", rating)
+		t.Fail()
+	}
+}
+
+func TestRDFa(t *testing.T) {
+	strContent := `
+	<html>
+		<body vocab="http://schema.org/" prefix="ex: http://example.org/ns#">
+			<div typeof="Person" resource="#me">
+				<span property="name">Alice</span>
+				<a property="url" href="/alice">home</a>
+				<span property="ex:team">Crawlers</span>
+				<div property="address" typeof="PostalAddress">
+					<span property="addressLocality">Brussels</span>
+				</div>
+			</div>
+			<meta property="og:title" content="Alice's page">
+		</body>
+	</html>
+	`
+	webResource := makeStructuredDataWebResource(t, strContent)
+
+	triples, err := webResource.RDFa()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	me := "https://example.com/page#me"
+	expected := []RDFaTriple{
+		{me, RDFTypeIRI, "http://schema.org/Person"},
+		{me, "http://schema.org/name", "Alice"},
+		{me, "http://schema.org/url", "https://example.com/alice"},
+		{me, "http://example.org/ns#team", "Crawlers"},
+		{me, "http://schema.org/address", "_:b0"},
+		{"_:b0", RDFTypeIRI, "http://schema.org/PostalAddress"},
+		{"_:b0", "http://schema.org/addressLocality", "Brussels"},
+		{"https://example.com/page", "http://ogp.me/ns#title", "Alice's page"},
+	}
+
+	if len(triples) != len(expected) {
+		t.Logf("Triples %v different of %v", triples, expected)
+		t.FailNow()
+	}
+
+	for index, triple := range triples {
+		if triple != expected[index] {
+			t.Logf("Triple %v different of %v", triple, expected[index])
+			t.Fail()
+		}
+	}
+}
+
+func TestOpenGraphAndTwitterCard(t *testing.T) {
+	strContent := `
+	<html>
+		<head>
+			<meta property="og:title" content="A title">
+			<meta property="og:image" content="https://example.com/1.png">
+			<meta property="og:image" content="https://example.com/2.png">
+			<meta property="article:author" content="Alice">
+			<meta property="description" content="Not open graph">
+			<meta name="twitter:card" content="summary_large_image">
+			<meta property="twitter:site" content="@example">
+			<meta name="twitter:card" content="ignored duplicate">
+		</head>
+	</html>
+	`
+	webResource := makeStructuredDataWebResource(t, strContent)
+
+	openGraph, err := webResource.OpenGraph()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if len(openGraph) != 3 || openGraph["og:title"][0] != "A title" || openGraph["article:author"][0] != "Alice" {
+		t.Logf("Wrong open graph %v", openGraph)
+		t.Fail()
+	}
+
+	expected := []string{"https://example.com/1.png", "https://example.com/2.png"}
+	if !textsMatch(expected, openGraph["og:image"]) {
+		t.Logf("Images %s different of %s", openGraph["og:image"], expected)
+		t.Fail()
+	}
+
+	card, err := webResource.TwitterCard()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if len(card) != 2 || card["twitter:card"] != "summary_large_image" || card["twitter:site"] != "@example" {
+		t.Logf("Wrong twitter card %v", card)
+		t.Fail()
+	}
+}
+
+func makeStructuredDataWebResource(t *testing.T, content string) *WebResource {
+	webResource, err := NewWebResource("https://example.com/page", "text/html", []byte(content))
+
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+
+	return webResource
+}
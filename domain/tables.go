@@ -0,0 +1,281 @@
+package domain
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// Table is an HTML table with its spanning cells expanded : a cell spanning
+// several rows or columns is repeated in each of them, so every row has as
+// many cells as the widest one.
+type Table struct {
+	Caption string
+	// Header holds the column names, from the <thead> rows or from a first
+	// row made of <th> cells only. It is nil when the table has none.
+	// Header rows are merged, e.g. "Price" above "Min" gives "Price Min".
+	Header []string
+	Rows   [][]string
+}
+
+// Tables returns the tables of the page in document order. Nested tables
+// are returned on their own and left out of the cells of their parent.
+func (resource *WebResource) Tables() ([]Table, error) {
+	tables := make([]Table, 0)
+	doc, err := resource.document()
+
+	if err != nil {
+		return tables, err
+	}
+
+	for _, n := range htmlquery.Find(doc, "//table") {
+		tables = append(tables, parseTable(n))
+	}
+
+	return tables, nil
+}
+
+// Records returns a map per data row, keyed by column name. Columns without
+// a name are named column_1, column_2... after their position and repeated
+// names get a _2, _3... suffix.
+func (table Table) Records() []map[string]string {
+	keys := table.keys()
+	records := make([]map[string]string, 0, len(table.Rows))
+
+	for _, row := range table.Rows {
+		record := make(map[string]string, len(keys))
+		for index, key := range keys {
+			if index < len(row) {
+				record[key] = row[index]
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// WriteCSV writes the header, if any, and the rows of the table as CSV.
+func (table Table) WriteCSV(writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+
+	if table.Header != nil {
+		if err := csvWriter.Write(table.Header); err != nil {
+			return err
+		}
+	}
+
+	if err := csvWriter.WriteAll(table.Rows); err != nil {
+		return err
+	}
+
+	return csvWriter.Error()
+}
+
+// CSV returns the table as CSV, see WriteCSV.
+func (table Table) CSV() (string, error) {
+	var builder strings.Builder
+	err := table.WriteCSV(&builder)
+
+	return builder.String(), err
+}
+
+func (table Table) keys() []string {
+	width := len(table.Header)
+	for _, row := range table.Rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	keys := make([]string, width)
+	seen := make(map[string]int)
+	for index := range keys {
+		key := ""
+		if index < len(table.Header) {
+			key = table.Header[index]
+		}
+		if key == "" {
+			key = fmt.Sprintf("column_%d", index+1)
+		}
+
+		seen[key]++
+		if seen[key] > 1 {
+			key = fmt.Sprintf("%s_%d", key, seen[key])
+		}
+		keys[index] = key
+	}
+
+	return keys
+}
+
+type tableCell struct {
+	text     string
+	isHeader bool
+}
+
+func parseTable(n *html.Node) Table {
+	table := Table{}
+	if caption := htmlquery.FindOne(n, "./caption"); caption != nil {
+		table.Caption = cellText(caption)
+	}
+
+	rows := make([]*html.Node, 0)
+	headerRows := 0
+	for _, row := range htmlquery.Find(n, ".//tr") {
+		if closestTable(row) != n {
+			continue
+		}
+
+		rows = append(rows, row)
+		if row.Parent != nil && row.Parent.Data == "thead" {
+			headerRows = len(rows)
+		}
+	}
+
+	grid := expandRows(rows)
+
+	// Without <thead>, a first row of <th> only is the header
+	if headerRows == 0 && len(grid) > 0 {
+		headerRows = 1
+		for _, cell := range grid[0] {
+			if !cell.isHeader {
+				headerRows = 0
+				break
+			}
+		}
+	}
+
+	if headerRows > 0 && len(grid) > 0 {
+		table.Header = mergeHeaderRows(grid[:headerRows])
+	}
+
+	table.Rows = make([][]string, 0, len(grid)-headerRows)
+	for _, cells := range grid[headerRows:] {
+		row := make([]string, 0, len(cells))
+		for _, cell := range cells {
+			row = append(row, cell.text)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table
+}
+
+// expandRows lays the cells of rows out on a grid, repeating the cells
+// spanning several rows or columns.
+func expandRows(rows []*html.Node) [][]tableCell {
+	grid := make([][]tableCell, len(rows))
+	filled := make([][]bool, len(rows))
+	width := 0
+
+	for rowIndex, row := range rows {
+		column := 0
+		for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.Type != html.ElementNode || cell.Data != "td" && cell.Data != "th" {
+				continue
+			}
+
+			for column < len(filled[rowIndex]) && filled[rowIndex][column] {
+				column++
+			}
+
+			colspan := spanAttribute(cell, "colspan", 1, 1000)
+			rowspan := spanAttribute(cell, "rowspan", 1, 65534)
+			if rowspan == 0 || rowIndex+rowspan > len(rows) {
+				// A rowspan of 0 spans every remaining row
+				rowspan = len(rows) - rowIndex
+			}
+
+			value := tableCell{text: cellText(cell), isHeader: cell.Data == "th"}
+			for spannedRow := rowIndex; spannedRow < rowIndex+rowspan; spannedRow++ {
+				for spannedColumn := column; spannedColumn < column+colspan; spannedColumn++ {
+					for len(grid[spannedRow]) <= spannedColumn {
+						grid[spannedRow] = append(grid[spannedRow], tableCell{})
+						filled[spannedRow] = append(filled[spannedRow], false)
+					}
+					grid[spannedRow][spannedColumn] = value
+					filled[spannedRow][spannedColumn] = true
+				}
+			}
+
+			column += colspan
+		}
+
+		if len(grid[rowIndex]) > width {
+			width = len(grid[rowIndex])
+		}
+	}
+
+	for index := range grid {
+		for len(grid[index]) < width {
+			grid[index] = append(grid[index], tableCell{})
+		}
+	}
+
+	return grid
+}
+
+// mergeHeaderRows names each column after its distinct header cells.
+func mergeHeaderRows(rows [][]tableCell) []string {
+	header := make([]string, len(rows[0]))
+
+	for column := range header {
+		names := make([]string, 0, len(rows))
+		for _, row := range rows {
+			text := row[column].text
+			if text != "" && (len(names) == 0 || names[len(names)-1] != text) {
+				names = append(names, text)
+			}
+		}
+		header[column] = strings.Join(names, " ")
+	}
+
+	return header
+}
+
+func spanAttribute(cell *html.Node, name string, defaultValue int, maxValue int) int {
+	value, ok := nodeAttr(cell, name)
+	if !ok {
+		return defaultValue
+	}
+
+	span, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || span < 0 || name == "colspan" && span == 0 {
+		return defaultValue
+	}
+	if span > maxValue {
+		return maxValue
+	}
+
+	return span
+}
+
+// cellText returns the text of a cell with spaces collapsed, leaving out
+// nested tables.
+func cellText(n *html.Node) string {
+	var text strings.Builder
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			text.WriteString(n.Data)
+		case n.Type == html.ElementNode && (n.Data == "table" || n.Data == "script" || n.Data == "style"):
+		case n.Type == html.ElementNode && n.Data == "br":
+			text.WriteByte(' ')
+		default:
+			for child := n.FirstChild; child != nil; child = child.NextSibling {
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+
+	return strings.Join(strings.Fields(text.String()), " ")
+}
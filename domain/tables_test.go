@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestTables(t *testing.T) {
+	strContent := `
+	<html>
+		<body>
+			<table>
+				<caption> Price list </caption>
+				<thead>
+					<tr><th rowspan="2">Product</th><th colspan="2">Price</th></tr>
+					<tr><th>Min</th><th>Max</th></tr>
+				</thead>
+				<tbody>
+					<tr><td>Gopher <b>plush</b></td><td>10</td><td>15</td></tr>
+					<tr><td rowspan="2">Sticker</td><td colspan="2">3</td></tr>
+					<tr><td>2</td></tr>
+				</tbody>
+			</table>
+			<table>
+				<tr><th>Day</th><th>Talk</th></tr>
+				<tr>
+					<td>Monday</td>
+					<td>Crawling<table><tr><td>nested</td><td>cell</td></tr></table></td>
+				</tr>
+			</table>
+			<table>
+				<tr><td>a</td><td>b, "c"</td></tr>
+			</table>
+		</body>
+	</html>
+	`
+	webResource, err := NewWebResource("https://example.com/", "text/html", []byte(strContent))
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+
+	tables, err := webResource.Tables()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if len(tables) != 4 {
+		t.Logf("Expected 4 tables, got %d", len(tables))
+		t.FailNow()
+	}
+
+	prices := tables[0]
+	if prices.Caption != "Price list" || !textsMatch([]string{"Product", "Price Min", "Price Max"}, prices.Header) {
+		t.Logf("Wrong caption %q or header %q", prices.Caption, prices.Header)
+		t.Fail()
+	}
+	expectedRows := [][]string{
+		{"Gopher plush", "10", "15"},
+		{"Sticker", "3", "3"},
+		{"Sticker", "2", ""},
+	}
+	if !rowsMatch(expectedRows, prices.Rows) {
+		t.Logf("Rows %q different of %q", prices.Rows, expectedRows)
+		t.Fail()
+	}
+
+	schedule := tables[1]
+	if !textsMatch([]string{"Day", "Talk"}, schedule.Header) || !rowsMatch([][]string{{"Monday", "Crawling"}}, schedule.Rows) {
+		t.Logf("Wrong header %q or rows %q", schedule.Header, schedule.Rows)
+		t.Fail()
+	}
+
+	nested := tables[2]
+	if nested.Header != nil || !rowsMatch([][]string{{"nested", "cell"}}, nested.Rows) {
+		t.Logf("Wrong nested table %+v", nested)
+		t.Fail()
+	}
+
+	records := prices.Records()
+	if len(records) != 3 || records[1]["Product"] != "Sticker" || records[1]["Price Max"] != "3" {
+		t.Logf("Wrong records %v", records)
+		t.Fail()
+	}
+
+	records = tables[3].Records()
+	if len(records) != 1 || records[0]["column_1"] != "a" || records[0]["column_2"] != `b, "c"` {
+		t.Logf("Wrong records without header %v", records)
+		t.Fail()
+	}
+
+	csv, err := prices.CSV()
+	expectedCSV := "Product,Price Min,Price Max\nGopher plush,10,15\nSticker,3,3\nSticker,2,\n"
+	if err != nil || csv != expectedCSV {
+		t.Logf("CSV %q different of %q", csv, expectedCSV)
+		t.Fail()
+	}
+
+	csv, _ = tables[3].CSV()
+	if csv != "a,\"b, \"\"c\"\"\"\n" {
+		t.Logf("Wrong quoted CSV %q", csv)
+		t.Fail()
+	}
+}
+
+func TestTableRecordsDuplicateColumns(t *testing.T) {
+	table := Table{Header: []string{"Name", "Name", ""}, Rows: [][]string{{"a", "b", "c", "d"}}}
+
+	records := table.Records()
+	expected := map[string]string{"Name": "a", "Name_2": "b", "column_3": "c", "column_4": "d"}
+
+	if len(records) != 1 || len(records[0]) != len(expected) {
+		t.Logf("Records %v different of %v", records, expected)
+		t.FailNow()
+	}
+
+	for key, value := range expected {
+		if records[0][key] != value {
+			t.Logf("Records %v different of %v", records, expected)
+			t.Fail()
+		}
+	}
+}
+
+func rowsMatch(expected [][]string, received [][]string) bool {
+	if len(expected) != len(received) {
+		return false
+	}
+
+	for index, row := range expected {
+		if !textsMatch(row, received[index]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,290 @@
+package domain
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// NormalizationRule rewrites a url into a canonical spelling.
+type NormalizationRule func(parsedUrl *url.URL)
+
+// TrackingParameters are the query parameters removed by
+// StripTrackingParameters. Names ending with "*" are prefixes.
+var TrackingParameters = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "gclsrc", "msclkid", "yclid",
+	"mc_cid", "mc_eid", "igshid", "_ga", "_gl", "_hsenc", "_hsmi",
+}
+
+// SessionIdParameters are the query and path parameters removed by
+// StripSessionIds. Names ending with "*" are prefixes.
+var SessionIdParameters = []string{
+	"jsessionid", "phpsessid", "aspsessionid*", "sessionid", "session_id", "cfid", "cftoken",
+}
+
+// DefaultNormalizationRules are the rules applied by NormalizeURL.
+// StripSessionIds is left out, some sites use those parameters to tell
+// pages apart.
+var DefaultNormalizationRules = []NormalizationRule{
+	LowercaseSchemeAndHost,
+	RemoveDefaultPort,
+	RemoveDotSegments,
+	StripFragment,
+	StripTrackingParameters,
+	SortQueryParameters,
+}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ftp":   "21",
+	"ws":    "80",
+	"wss":   "443",
+}
+
+// URLNormalizer rewrites urls so that different spellings of the same
+// resource compare equal.
+type URLNormalizer struct {
+	rules []NormalizationRule
+}
+
+// NewURLNormalizer returns a normalizer applying rules in order.
+func NewURLNormalizer(rules ...NormalizationRule) URLNormalizer {
+	return URLNormalizer{rules: rules}
+}
+
+func (normalizer URLNormalizer) Normalize(rawUrl string) (string, error) {
+	parsedUrl, err := url.Parse(rawUrl)
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, rule := range normalizer.rules {
+		rule(parsedUrl)
+	}
+
+	return parsedUrl.String(), nil
+}
+
+// NormalizeURL normalizes rawUrl with the DefaultNormalizationRules.
+func NormalizeURL(rawUrl string) (string, error) {
+	return NewURLNormalizer(DefaultNormalizationRules...).Normalize(rawUrl)
+}
+
+func LowercaseSchemeAndHost(parsedUrl *url.URL) {
+	parsedUrl.Scheme = strings.ToLower(parsedUrl.Scheme)
+	parsedUrl.Host = strings.ToLower(parsedUrl.Host)
+}
+
+func RemoveDefaultPort(parsedUrl *url.URL) {
+	port := parsedUrl.Port()
+	if port == "" || defaultPorts[strings.ToLower(parsedUrl.Scheme)] != port {
+		return
+	}
+
+	parsedUrl.Host = strings.TrimSuffix(parsedUrl.Host, ":"+port)
+}
+
+// RemoveDotSegments removes the "." and ".." segments of the path as
+// described by RFC 3986 section 5.2.4. An empty path of a url with a host
+// becomes "/".
+func RemoveDotSegments(parsedUrl *url.URL) {
+	if parsedUrl.Opaque != "" {
+		return
+	}
+
+	escapedPath := removeDotSegments(parsedUrl.EscapedPath())
+	if escapedPath == "" && parsedUrl.Host != "" {
+		escapedPath = "/"
+	}
+
+	setEscapedPath(parsedUrl, escapedPath)
+}
+
+func StripFragment(parsedUrl *url.URL) {
+	parsedUrl.Fragment = ""
+	parsedUrl.RawFragment = ""
+}
+
+// SortQueryParameters orders the query parameters by name, keeping the
+// order of the values of a same parameter.
+func SortQueryParameters(parsedUrl *url.URL) {
+	parameters := splitQuery(parsedUrl.RawQuery)
+	sort.SliceStable(parameters, func(i int, j int) bool {
+		return queryParameterName(parameters[i]) < queryParameterName(parameters[j])
+	})
+	parsedUrl.RawQuery = strings.Join(parameters, "&")
+}
+
+// StripTrackingParameters removes the TrackingParameters from the query.
+func StripTrackingParameters(parsedUrl *url.URL) {
+	StripQueryParameters(TrackingParameters...)(parsedUrl)
+}
+
+// StripSessionIds removes the SessionIdParameters from the query and the
+// path, where they appear as ";jsessionid=..." path parameters.
+func StripSessionIds(parsedUrl *url.URL) {
+	StripQueryParameters(SessionIdParameters...)(parsedUrl)
+
+	if parsedUrl.Opaque != "" {
+		return
+	}
+
+	segments := strings.Split(parsedUrl.EscapedPath(), "/")
+	for index, segment := range segments {
+		parts := strings.Split(segment, ";")
+		kept := parts[:1]
+		for _, parameter := range parts[1:] {
+			if !matchParameterName(queryParameterName(parameter), SessionIdParameters) {
+				kept = append(kept, parameter)
+			}
+		}
+		segments[index] = strings.Join(kept, ";")
+	}
+
+	setEscapedPath(parsedUrl, strings.Join(segments, "/"))
+}
+
+// StripQueryParameters returns a rule removing the query parameters called
+// after one of names. Names ending with "*" are prefixes. Names are
+// compared without case.
+func StripQueryParameters(names ...string) NormalizationRule {
+	return func(parsedUrl *url.URL) {
+		if parsedUrl.RawQuery == "" {
+			return
+		}
+
+		kept := make([]string, 0)
+		for _, parameter := range splitQuery(parsedUrl.RawQuery) {
+			if !matchParameterName(queryParameterName(parameter), names) {
+				kept = append(kept, parameter)
+			}
+		}
+
+		parsedUrl.RawQuery = strings.Join(kept, "&")
+		if len(kept) == 0 {
+			parsedUrl.ForceQuery = false
+		}
+	}
+}
+
+// AddTrailingSlash ends with a slash the paths whose last segment does not
+// look like a file name, that is has no extension.
+func AddTrailingSlash(parsedUrl *url.URL) {
+	escapedPath := parsedUrl.EscapedPath()
+	if escapedPath == "" || strings.HasSuffix(escapedPath, "/") {
+		return
+	}
+
+	lastSegment := escapedPath[strings.LastIndex(escapedPath, "/")+1:]
+	if strings.Contains(lastSegment, ".") {
+		return
+	}
+
+	setEscapedPath(parsedUrl, escapedPath+"/")
+}
+
+// RemoveTrailingSlash removes the trailing slashes of the path, except for
+// the root path.
+func RemoveTrailingSlash(parsedUrl *url.URL) {
+	escapedPath := strings.TrimRight(parsedUrl.EscapedPath(), "/")
+	if escapedPath == "" && parsedUrl.Path != "" {
+		escapedPath = "/"
+	}
+
+	setEscapedPath(parsedUrl, escapedPath)
+}
+
+func removeDotSegments(path string) string {
+	output := make([]string, 0)
+	input := path
+
+	for input != "" {
+		switch {
+		case strings.HasPrefix(input, "../"):
+			input = input[3:]
+		case strings.HasPrefix(input, "./"):
+			input = input[2:]
+		case strings.HasPrefix(input, "/./"):
+			input = input[2:]
+		case input == "/.":
+			input = "/"
+		case strings.HasPrefix(input, "/../"):
+			input = input[3:]
+			if len(output) > 0 {
+				output = output[:len(output)-1]
+			}
+		case input == "/..":
+			input = "/"
+			if len(output) > 0 {
+				output = output[:len(output)-1]
+			}
+		case input == "." || input == "..":
+			input = ""
+		default:
+			// Move the first segment, with its leading slash, to the output
+			end := strings.Index(input[1:], "/")
+			if end < 0 {
+				output = append(output, input)
+				input = ""
+			} else {
+				output = append(output, input[:end+1])
+				input = input[end+1:]
+			}
+		}
+	}
+
+	return strings.Join(output, "")
+}
+
+func setEscapedPath(parsedUrl *url.URL, escapedPath string) {
+	path, err := url.PathUnescape(escapedPath)
+	if err != nil {
+		return
+	}
+
+	parsedUrl.Path = path
+	parsedUrl.RawPath = escapedPath
+}
+
+func splitQuery(rawQuery string) []string {
+	parameters := make([]string, 0)
+	for _, parameter := range strings.Split(rawQuery, "&") {
+		if parameter != "" {
+			parameters = append(parameters, parameter)
+		}
+	}
+
+	return parameters
+}
+
+func queryParameterName(parameter string) string {
+	name := parameter
+	if index := strings.Index(parameter, "="); index >= 0 {
+		name = parameter[:index]
+	}
+
+	if unescapedName, err := url.QueryUnescape(name); err == nil {
+		return unescapedName
+	}
+
+	return name
+}
+
+func matchParameterName(name string, names []string) bool {
+	name = strings.ToLower(name)
+
+	for _, candidate := range names {
+		candidate = strings.ToLower(candidate)
+		if strings.HasSuffix(candidate, "*") {
+			if strings.HasPrefix(name, strings.TrimSuffix(candidate, "*")) {
+				return true
+			}
+		} else if name == candidate {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,130 @@
+package domain
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	cases := []struct {
+		rawUrl   string
+		expected string
+	}{
+		{"HTTP://Example.COM/Path", "http://example.com/Path"},
+		{"http://example.com", "http://example.com/"},
+		{"http://example.com:80/", "http://example.com/"},
+		{"https://example.com:443/", "https://example.com/"},
+		{"https://example.com:8443/", "https://example.com:8443/"},
+		{"http://example.com:443/", "http://example.com:443/"},
+		{"http://example.com/a/./b/../c", "http://example.com/a/c"},
+		{"http://example.com/a/b/../../..", "http://example.com/"},
+		{"http://example.com/a/%2E%2E/b", "http://example.com/a/%2E%2E/b"},
+		{"http://example.com/?b=2&a=1&b=1", "http://example.com/?a=1&b=2&b=1"},
+		{"http://example.com/page#section", "http://example.com/page"},
+		{"http://example.com/?utm_source=news&utm_medium=mail&id=3", "http://example.com/?id=3"},
+		{"http://example.com/?fbclid=abc&gclid=def", "http://example.com/"},
+		{"http://example.com/?UTM_Campaign=x&q=go", "http://example.com/?q=go"},
+		{"http://example.com/cart?PHPSESSID=42&item=1", "http://example.com/cart?PHPSESSID=42&item=1"},
+		{"http://example.com/a%20b/?q=a%20b", "http://example.com/a%20b/?q=a%20b"},
+		{"http://example.com/files/a%2Fb", "http://example.com/files/a%2Fb"},
+	}
+
+	for _, c := range cases {
+		normalized, err := NormalizeURL(c.rawUrl)
+
+		if err != nil {
+			t.Logf("Could not normalize %s : %s", c.rawUrl, err)
+			t.Fail()
+			continue
+		}
+
+		if normalized != c.expected {
+			t.Logf("%s : %s different of %s", c.rawUrl, normalized, c.expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestNormalizeURLSameSpellings(t *testing.T) {
+	spellings := []string{
+		"http://example.com/products/?id=3&color=red",
+		"HTTP://EXAMPLE.COM:80/products/?color=red&id=3",
+		"http://example.com/shop/../products/./?id=3&color=red#reviews",
+		"http://example.com/products/?utm_source=twitter&color=red&id=3&fbclid=xyz",
+	}
+
+	expected, _ := NormalizeURL(spellings[0])
+	for _, spelling := range spellings {
+		normalized, _ := NormalizeURL(spelling)
+		if normalized != expected {
+			t.Logf("%s normalized to %s instead of %s", spelling, normalized, expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestStripSessionIds(t *testing.T) {
+	normalizer := NewURLNormalizer(StripSessionIds)
+
+	cases := []struct {
+		rawUrl   string
+		expected string
+	}{
+		{"http://example.com/cart;jsessionid=0A1B2C?item=1", "http://example.com/cart?item=1"},
+		{"http://example.com/cart?PHPSESSID=42&item=1", "http://example.com/cart?item=1"},
+		{"http://example.com/forum?sid=12", "http://example.com/forum?sid=12"},
+	}
+
+	for _, c := range cases {
+		normalized, err := normalizer.Normalize(c.rawUrl)
+
+		if err != nil || normalized != c.expected {
+			t.Logf("%s : %s different of %s", c.rawUrl, normalized, c.expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestTrailingSlashRules(t *testing.T) {
+	adding := NewURLNormalizer(AddTrailingSlash)
+	removing := NewURLNormalizer(RemoveTrailingSlash)
+
+	cases := []struct {
+		normalizer URLNormalizer
+		rawUrl     string
+		expected   string
+	}{
+		{adding, "http://example.com/docs", "http://example.com/docs/"},
+		{adding, "http://example.com/docs/", "http://example.com/docs/"},
+		{adding, "http://example.com/docs/index.html", "http://example.com/docs/index.html"},
+		{adding, "http://example.com/docs?page=2", "http://example.com/docs/?page=2"},
+		{removing, "http://example.com/docs/", "http://example.com/docs"},
+		{removing, "http://example.com/docs//", "http://example.com/docs"},
+		{removing, "http://example.com/", "http://example.com/"},
+	}
+
+	for _, c := range cases {
+		normalized, err := c.normalizer.Normalize(c.rawUrl)
+
+		if err != nil || normalized != c.expected {
+			t.Logf("%s : %s different of %s", c.rawUrl, normalized, c.expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestCustomNormalizationRules(t *testing.T) {
+	normalizer := NewURLNormalizer(StripQueryParameters("ref", "tab_*"), SortQueryParameters)
+
+	normalized, err := normalizer.Normalize("http://Example.com/?tab_id=4&z=1&ref=home&a=2#top")
+	expected := "http://Example.com/?a=2&z=1#top"
+
+	if err != nil || normalized != expected {
+		t.Logf("%s different of %s", normalized, expected)
+		t.Fail()
+	}
+
+	_, err = normalizer.Normalize("http://[::1")
+
+	if err == nil {
+		t.Log("Invalid url should not be normalized")
+		t.Fail()
+	}
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// UrlResolution selects how the urls found in a page are made absolute.
+type UrlResolution int
+
+const (
+	// StandardResolution resolves urls as references against the base url
+	// of the document, following RFC 3986. This is the default.
+	StandardResolution UrlResolution = iota
+	// LegacyResolution guesses absolute urls with MakeUrlCannonical.
+	LegacyResolution
+)
+
+// ResolveURL resolves the reference rawUrl against base following RFC 3986.
+// Surrounding whitespaces and embedded tabs and newlines are dropped first,
+// as browsers do for attributes holding urls.
+func ResolveURL(rawUrl string, base *url.URL) (string, error) {
+	rawUrl = strings.TrimSpace(rawUrl)
+	rawUrl = strings.NewReplacer("\t", "", "\n", "", "\r", "").Replace(rawUrl)
+
+	reference, err := url.Parse(rawUrl)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(reference).String(), nil
+}
+
+func (resource *WebResource) resolveUrl(rawUrl string, base *url.URL) (string, error) {
+	if resource.resolution == LegacyResolution {
+		return MakeUrlCannonical(rawUrl, resource.url)
+	}
+
+	return ResolveURL(rawUrl, base)
+}
+
+// baseUrl returns the url relative urls of doc are resolved against : the
+// first <base href> of the document, itself resolved against the url of the
+// resource, or the url of the resource.
+func (resource *WebResource) baseUrl(doc *html.Node) *url.URL {
+	baseNode := htmlquery.FindOne(doc, "//base[@href]")
+	if baseNode == nil {
+		return resource.url
+	}
+
+	baseUrl, err := ResolveURL(htmlquery.SelectAttr(baseNode, "href"), resource.url)
+	if err != nil {
+		return resource.url
+	}
+
+	parsedBaseUrl, err := url.Parse(baseUrl)
+	if err != nil {
+		return resource.url
+	}
+
+	return parsedBaseUrl
+}
@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestResolveURL(t *testing.T) {
+	// Examples of RFC 3986 section 5.4, followed by the cases the legacy
+	// heuristics got wrong.
+	cases := []struct {
+		base     string
+		rawUrl   string
+		expected string
+	}{
+		{"http://a/b/c/d;p?q", "g:h", "g:h"},
+		{"http://a/b/c/d;p?q", "g", "http://a/b/c/g"},
+		{"http://a/b/c/d;p?q", "./g", "http://a/b/c/g"},
+		{"http://a/b/c/d;p?q", "g/", "http://a/b/c/g/"},
+		{"http://a/b/c/d;p?q", "/g", "http://a/g"},
+		{"http://a/b/c/d;p?q", "//g", "http://g"},
+		{"http://a/b/c/d;p?q", "?y", "http://a/b/c/d;p?y"},
+		{"http://a/b/c/d;p?q", "g?y", "http://a/b/c/g?y"},
+		{"http://a/b/c/d;p?q", "#s", "http://a/b/c/d;p?q#s"},
+		{"http://a/b/c/d;p?q", "g#s", "http://a/b/c/g#s"},
+		{"http://a/b/c/d;p?q", "g?y#s", "http://a/b/c/g?y#s"},
+		{"http://a/b/c/d;p?q", ";x", "http://a/b/c/;x"},
+		{"http://a/b/c/d;p?q", "g;x", "http://a/b/c/g;x"},
+		{"http://a/b/c/d;p?q", "g;x?y#s", "http://a/b/c/g;x?y#s"},
+		{"http://a/b/c/d;p?q", "", "http://a/b/c/d;p?q"},
+		{"http://a/b/c/d;p?q", ".", "http://a/b/c/"},
+		{"http://a/b/c/d;p?q", "./", "http://a/b/c/"},
+		{"http://a/b/c/d;p?q", "..", "http://a/b/"},
+		{"http://a/b/c/d;p?q", "../", "http://a/b/"},
+		{"http://a/b/c/d;p?q", "../g", "http://a/b/g"},
+		{"http://a/b/c/d;p?q", "../..", "http://a/"},
+		{"http://a/b/c/d;p?q", "../../", "http://a/"},
+		{"http://a/b/c/d;p?q", "../../g", "http://a/g"},
+		{"http://a/b/c/d;p?q", "../../../g", "http://a/g"},
+		{"http://a/b/c/d;p?q", "../../../../g", "http://a/g"},
+		{"http://a/b/c/d;p?q", "/./g", "http://a/g"},
+		{"http://a/b/c/d;p?q", "/../g", "http://a/g"},
+		{"http://a/b/c/d;p?q", "g.", "http://a/b/c/g."},
+		{"http://a/b/c/d;p?q", ".g", "http://a/b/c/.g"},
+		{"http://a/b/c/d;p?q", "g..", "http://a/b/c/g.."},
+		{"http://a/b/c/d;p?q", "..g", "http://a/b/c/..g"},
+		{"http://a/b/c/d;p?q", "./../g", "http://a/b/g"},
+		{"http://a/b/c/d;p?q", "./g/.", "http://a/b/c/g/"},
+		{"http://a/b/c/d;p?q", "g/./h", "http://a/b/c/g/h"},
+		{"http://a/b/c/d;p?q", "g/../h", "http://a/b/c/h"},
+		{"http://a/b/c/d;p?q", "g;x=1/./y", "http://a/b/c/g;x=1/y"},
+		{"http://a/b/c/d;p?q", "g;x=1/../y", "http://a/b/c/y"},
+		{"http://a/b/c/d;p?q", "g?y/./x", "http://a/b/c/g?y/./x"},
+		{"http://a/b/c/d;p?q", "g?y/../x", "http://a/b/c/g?y/../x"},
+		{"http://a/b/c/d;p?q", "g#s/./x", "http://a/b/c/g#s/./x"},
+		{"http://a/b/c/d;p?q", "g#s/../x", "http://a/b/c/g#s/../x"},
+		{"https://example.com/company/", "about/team", "https://example.com/company/about/team"},
+		{"https://example.com/blog/post.html", "../img.gif", "https://example.com/img.gif"},
+		{"https://example.com/blog/posts/post.html", "../img.gif", "https://example.com/blog/img.gif"},
+		{"https://example.com/list?page=1", "?page=2", "https://example.com/list?page=2"},
+		{"https://example.com/doc.html", "#frag", "https://example.com/doc.html#frag"},
+		{"https://example.com/", "//cdn.example.com/x.js", "https://cdn.example.com/x.js"},
+		{"http://example.com/", "//cdn.example.com/x.js", "http://cdn.example.com/x.js"},
+		{"https://example.com/a/b", "picture.webp", "https://example.com/a/picture.webp"},
+		{"https://example.com/a/b", "archive.tar.gz", "https://example.com/a/archive.tar.gz"},
+		{"https://example.com/a/b", "index", "https://example.com/a/index"},
+		{"https://example.com/a/b", "google.com", "https://example.com/a/google.com"},
+		{"https://example.com/a/b", "https://google.com/search?q=go", "https://google.com/search?q=go"},
+		{"https://example.com/a/b", "mailto:someone@example.com", "mailto:someone@example.com"},
+		{"https://example.com/a/b", "  /trimmed.html  ", "https://example.com/trimmed.html"},
+		{"https://example.com/a/b", "/line\nbreak.html", "https://example.com/linebreak.html"},
+		{"https://example.com/a/b", "/with space.html", "https://example.com/with%20space.html"},
+		{"https://example.com:8443/a/b", "c", "https://example.com:8443/a/c"},
+	}
+
+	for _, c := range cases {
+		base, err := url.Parse(c.base)
+		if err != nil {
+			t.Logf("Could not parse base %s", c.base)
+			t.FailNow()
+		}
+
+		resolved, err := ResolveURL(c.rawUrl, base)
+		if err != nil {
+			t.Logf("Could not resolve %q against %s : %s", c.rawUrl, c.base, err)
+			t.Fail()
+			continue
+		}
+
+		if resolved != c.expected {
+			t.Logf("%q against %s : %s different of %s", c.rawUrl, c.base, resolved, c.expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestResolveURLInvalid(t *testing.T) {
+	base, _ := url.Parse("https://example.com/")
+
+	_, err := ResolveURL("http://[::1", base)
+
+	if err == nil {
+		t.Log("Invalid url should not be resolved")
+		t.Fail()
+	}
+}
+
+func TestBaseHref(t *testing.T) {
+	strContent := `
+	<html>
+		<head>
+			<base href="/static/v2/">
+			<link rel="stylesheet" href="css/style.css"/>
+		</head>
+		<body>
+			<a href="../about/team"></a>
+			<a href="?page=2"></a>
+			<img src="//cdn.example.com/logo.png">
+		</body>
+	</html>
+	`
+	webResource, err := NewWebResource("https://example.com/blog/post.html", "text/html", []byte(strContent))
+
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+
+	links, err := webResource.LinksUrls()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	expected := []string{"https://example.com/static/about/team", "https://example.com/static/v2/?page=2"}
+	if !linksMatch(expected, links) {
+		t.Logf("Extracted urls %s does not match expected ones %s", links, expected)
+		t.Fail()
+	}
+
+	styleSheets, _ := webResource.StyleSheetsUrls()
+	expected = []string{"https://example.com/static/v2/css/style.css"}
+	if !linksMatch(expected, styleSheets) {
+		t.Logf("Extracted urls %s does not match expected ones %s", styleSheets, expected)
+		t.Fail()
+	}
+
+	images, _ := webResource.ImagesUrls()
+	expected = []string{"https://cdn.example.com/logo.png"}
+	if !linksMatch(expected, images) {
+		t.Logf("Extracted urls %s does not match expected ones %s", images, expected)
+		t.Fail()
+	}
+}
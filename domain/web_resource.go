@@ -4,15 +4,21 @@ import (
 	"log"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
 )
 
 type WebResource struct {
 	url         *url.URL
 	contentType string
 	rawContent  []byte
-	htmlContent *string
+	dom         *domCache
+	attempts    int
+	resolution  UrlResolution
+
+	responseMetadata ResponseMetadata
 }
 
 func NewWebResource(webUrl string, contentType string, rawContent []byte) (*WebResource, error) {
@@ -26,108 +32,35 @@ func NewWebResource(webUrl string, contentType string, rawContent []byte) (*WebR
 		url:         parsedUrl,
 		contentType: contentType,
 		rawContent:  rawContent,
-		htmlContent: nil,
+		dom:         &domCache{},
+		attempts:    1,
+		responseMetadata: ResponseMetadata{
+			RequestURL:    webUrl,
+			ContentLength: int64(len(rawContent)),
+		},
 	}, nil
 }
 
+// IsWebPage reports whether the resource is an HTML page, based on its
+// MediaType.
 func (resource WebResource) IsWebPage() bool {
-	return strings.Contains(resource.contentType, "text/html") || strings.Contains(resource.contentType, "text/htm")
+	return isHTMLMediaType(resource.MediaType())
 }
 
 func (resource *WebResource) StyleSheetsUrls() ([]string, error) {
-	resource.parseHtml()
-
-	urls := make([]string, 0)
-	doc, err := htmlquery.Parse(strings.NewReader(*resource.htmlContent))
-
-	if err != nil {
-		return urls, err
-	}
-
-	list := htmlquery.Find(doc, "//link[@rel='stylesheet']/@href")
-	for _, n := range list {
-		url := htmlquery.SelectAttr(n, "href")
-		cannonicalUrl, err := MakeUrlCannonical(url, resource.url)
-		if err != nil {
-			log.Printf("Could not parse url %s\n", url)
-		} else {
-			urls = append(urls, cannonicalUrl)
-		}
-	}
-
-	return urls, nil
+	return resource.extractUrls("//link[@rel='stylesheet']/@href", "href")
 }
 
 func (resource *WebResource) ImagesUrls() ([]string, error) {
-	resource.parseHtml()
-
-	urls := make([]string, 0)
-	doc, err := htmlquery.Parse(strings.NewReader(*resource.htmlContent))
-
-	if err != nil {
-		return urls, err
-	}
-
-	list := htmlquery.Find(doc, "//img/@src")
-	for _, n := range list {
-		url := htmlquery.SelectAttr(n, "src")
-		cannonicalUrl, err := MakeUrlCannonical(url, resource.url)
-		if err != nil {
-			log.Printf("Could not parse url %s\n", url)
-		} else {
-			urls = append(urls, cannonicalUrl)
-		}
-	}
-
-	return urls, nil
+	return resource.extractUrls("//img/@src", "src")
 }
 
 func (resource *WebResource) JavascriptUrls() ([]string, error) {
-	resource.parseHtml()
-
-	urls := make([]string, 0)
-	doc, err := htmlquery.Parse(strings.NewReader(*resource.htmlContent))
-
-	if err != nil {
-		return urls, err
-	}
-
-	list := htmlquery.Find(doc, "//script/@src")
-	for _, n := range list {
-		url := htmlquery.SelectAttr(n, "src")
-		cannonicalUrl, err := MakeUrlCannonical(url, resource.url)
-		if err != nil {
-			log.Printf("Could not parse url %s\n", url)
-		} else {
-			urls = append(urls, cannonicalUrl)
-		}
-	}
-
-	return urls, nil
+	return resource.extractUrls("//script/@src", "src")
 }
 
 func (resource *WebResource) LinksUrls() ([]string, error) {
-	resource.parseHtml()
-
-	urls := make([]string, 0)
-	doc, err := htmlquery.Parse(strings.NewReader(*resource.htmlContent))
-
-	if err != nil {
-		return urls, err
-	}
-
-	list := htmlquery.Find(doc, "//a/@href")
-	for _, n := range list {
-		urlString := htmlquery.SelectAttr(n, "href")
-		cannonicalUrl, err := MakeUrlCannonical(urlString, resource.url)
-		if err != nil {
-			log.Printf("Could not parse url %s\n", urlString)
-		} else {
-			urls = append(urls, cannonicalUrl)
-		}
-	}
-
-	return urls, nil
+	return resource.extractUrls("//a/@href", "href")
 }
 
 func (resource *WebResource) InternalLinksUrls() ([]string, error) {
@@ -163,19 +96,88 @@ func (resource WebResource) URI() string {
 	return resource.url.RequestURI()
 }
 
+// Attempts returns how many downloads it took to fetch the resource.
+func (resource WebResource) Attempts() int {
+	return resource.attempts
+}
+
 func (resource *WebResource) ChangeRawContent(content []byte) {
 	resource.rawContent = content
+	resource.dom = &domCache{}
 }
 
-func (resource *WebResource) parseHtml() {
-	if !resource.IsWebPage() {
-		panic("Only web pages can be parsed to html")
+func (resource *WebResource) ChangeAttempts(attempts int) {
+	resource.attempts = attempts
+}
+
+// ChangeUrlResolution selects how extractors turn the urls found in the page
+// into absolute urls.
+func (resource *WebResource) ChangeUrlResolution(resolution UrlResolution) {
+	resource.resolution = resolution
+}
+
+// extractUrls returns the attribute of the nodes matching expr, resolved
+// into absolute urls. Urls that cannot be parsed are skipped.
+func (resource *WebResource) extractUrls(expr string, attribute string) ([]string, error) {
+	urls := make([]string, 0)
+	doc, err := resource.document()
+
+	if err != nil {
+		return urls, err
+	}
+
+	base := resource.baseUrl(doc)
+	for _, n := range htmlquery.Find(doc, expr) {
+		rawUrl := htmlquery.SelectAttr(n, attribute)
+		resolvedUrl, err := resource.resolveUrl(rawUrl, base)
+		if err != nil {
+			log.Printf("Could not parse url %s\n", rawUrl)
+		} else {
+			urls = append(urls, resolvedUrl)
+		}
+	}
+
+	return urls, nil
+}
+
+// domCache holds the decoded text and the HTML tree of a WebResource,
+// computed on first use.
+type domCache struct {
+	textOnce sync.Once
+	text     string
+	charset  string
+
+	once sync.Once
+	doc  *html.Node
+	err  error
+}
+
+// cache returns the cache of the resource. Resources not built with
+// NewWebResource get a throwaway one.
+func (resource *WebResource) cache() *domCache {
+	if resource.dom == nil {
+		return &domCache{}
 	}
 
-	if resource.htmlContent == nil {
-		stringContent := string(resource.rawContent)
-		resource.htmlContent = &stringContent
+	return resource.dom
+}
+
+// document returns the HTML tree of the resource. It is parsed once and
+// shared by every extractor, so the returned nodes must not be modified.
+// ErrNotHTML is returned for resources that are not web pages.
+func (resource *WebResource) document() (*html.Node, error) {
+	if !resource.IsWebPage() {
+		return nil, ErrNotHTML
 	}
+
+	dom := resource.cache()
+	text, _ := resource.decode(dom)
+
+	dom.once.Do(func() {
+		dom.doc, dom.err = htmlquery.Parse(strings.NewReader(text))
+	})
+
+	return dom.doc, dom.err
 }
 
 func MakeUrlCannonical(rawUrl string, parentUrl *url.URL) (string, error) {
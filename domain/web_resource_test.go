@@ -1,7 +1,10 @@
 package domain
 
 import (
+	"fmt"
 	"net/url"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -113,7 +116,7 @@ func TestInternalWebLinks(t *testing.T) {
 		t.FailNow()
 	}
 
-	expected := []string{"https://example.com/hello1.html", "http://example.com/hello2.html"}
+	expected := []string{"https://example.com/hello1.html", "http://example.com/hello2.html", "https://example.com/google.com/hello3.html"}
 	if !linksMatch(expected, links) {
 		t.Logf("Extracted urls %s does not match expected ones %s", links, expected)
 		t.Fail()
@@ -146,7 +149,7 @@ func TestWebLinks(t *testing.T) {
 		t.FailNow()
 	}
 
-	expected := []string{"https://example.com/hello1.html", "http://example.com/hello2.html", "http://google.com/hello3.html"}
+	expected := []string{"https://example.com/hello1.html", "http://example.com/hello2.html", "https://example.com/google.com/hello3.html"}
 	if !linksMatch(expected, links) {
 		t.Logf("Extracted urls %s does not match expected ones %s", links, expected)
 		t.Fail()
@@ -182,7 +185,7 @@ func TestImages(t *testing.T) {
 		t.FailNow()
 	}
 
-	expected := []string{"https://example.com/assets/test.jpg", "https://example.com/image.png", "http://google.com/hello3.png"}
+	expected := []string{"https://example.com/assets/test.jpg", "https://example.com/image.png", "https://example.com/google.com/hello3.png"}
 	if !linksMatch(expected, links) {
 		t.Logf("Extracted urls %s does not match expected ones %s", links, expected)
 		t.Fail()
@@ -285,6 +288,138 @@ func TestChangeWebContent(t *testing.T) {
 	}
 }
 
+func TestLegacyUrlResolution(t *testing.T) {
+	strContent := `
+	<html>
+		<body>
+			<a href="hello1.html"></a>
+			<a href="http://example.com/hello2.html"></a>
+			<a href="google.com/hello3.html"></a>
+		</body>
+
+	</html>
+	`
+	content := []byte(strContent)
+	webResource, err := NewWebResource("https://example.com/home", "text/html", content)
+
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+
+	webResource.ChangeUrlResolution(LegacyResolution)
+
+	links, err := webResource.LinksUrls()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	expected := []string{"https://example.com/hello1.html", "http://example.com/hello2.html", "http://google.com/hello3.html"}
+	if !linksMatch(expected, links) {
+		t.Logf("Extracted urls %s does not match expected ones %s", links, expected)
+		t.Fail()
+	}
+
+	links, err = webResource.InternalLinksUrls()
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	expected = []string{"https://example.com/hello1.html", "http://example.com/hello2.html"}
+	if !linksMatch(expected, links) {
+		t.Logf("Extracted urls %s does not match expected ones %s", links, expected)
+		t.Fail()
+	}
+}
+
+func TestChangeWebContentDropsDocument(t *testing.T) {
+	webResource, err := NewWebResource("https://example.com/home", "text/html", []byte(`<a href="/before"></a>`))
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+
+	links, _ := webResource.LinksUrls()
+	expected := []string{"https://example.com/before"}
+	if !linksMatch(expected, links) {
+		t.Logf("Extracted urls %s does not match expected ones %s", links, expected)
+		t.Fail()
+	}
+
+	webResource.ChangeRawContent([]byte(`<a href="/after"></a>`))
+
+	links, _ = webResource.LinksUrls()
+	expected = []string{"https://example.com/after"}
+	if !linksMatch(expected, links) {
+		t.Logf("Extracted urls %s does not match expected ones %s", links, expected)
+		t.Fail()
+	}
+}
+
+func TestDocumentIsParsedOnce(t *testing.T) {
+	webResource, err := NewWebResource("https://example.com/home", "text/html", makeLargePage(10))
+	if err != nil {
+		t.Log("Could not create WebResource")
+		t.FailNow()
+	}
+
+	var wg sync.WaitGroup
+	docs := make(chan interface{}, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			doc, _ := webResource.document()
+			docs <- doc
+			webResource.ImagesUrls()
+		}()
+	}
+	wg.Wait()
+	close(docs)
+
+	first := <-docs
+	for doc := range docs {
+		if doc != first {
+			t.Log("Document should be parsed a single time")
+			t.FailNow()
+		}
+	}
+}
+
+func BenchmarkExtractorsCachedDocument(b *testing.B) {
+	content := makeLargePage(2000)
+	b.SetBytes(int64(len(content)))
+
+	for i := 0; i < b.N; i++ {
+		webResource, _ := NewWebResource("https://example.com/home", "text/html", content)
+		webResource.LinksUrls()
+		webResource.ImagesUrls()
+		webResource.JavascriptUrls()
+		webResource.StyleSheetsUrls()
+	}
+}
+
+func BenchmarkExtractorsUncachedDocument(b *testing.B) {
+	content := makeLargePage(2000)
+	b.SetBytes(int64(len(content)))
+
+	for i := 0; i < b.N; i++ {
+		webResource, _ := NewWebResource("https://example.com/home", "text/html", content)
+		// Changing the content drops the cached document, as before caching
+		webResource.LinksUrls()
+		webResource.ChangeRawContent(content)
+		webResource.ImagesUrls()
+		webResource.ChangeRawContent(content)
+		webResource.JavascriptUrls()
+		webResource.ChangeRawContent(content)
+		webResource.StyleSheetsUrls()
+	}
+}
+
 func TestMakeUrlCannonical(t *testing.T) {
 	parsedUrl, _ := url.Parse("https://example.com")
 
@@ -354,3 +489,14 @@ func contentMatch(expected []byte, received []byte) bool {
 	}
 	return true
 }
+
+func makeLargePage(items int) []byte {
+	var page strings.Builder
+	page.WriteString(`<html><head><link rel="stylesheet" href="/style.css"><script src="/app.js"></script></head><body>`)
+	for i := 0; i < items; i++ {
+		fmt.Fprintf(&page, `<div class="item"><a href="/item/%d">Item %d</a><img src="/img/%d.png"><p>Some text about item %d.</p></div>`, i, i, i, i)
+	}
+	page.WriteString(`</body></html>`)
+
+	return []byte(page.String())
+}
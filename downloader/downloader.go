@@ -1,7 +1,33 @@
 package downloader
 
-import "github.com/lauevrar77/dyzone/domain"
+import (
+	"context"
+
+	"github.com/lauevrar77/dyzone/domain"
+)
 
 type Downloader interface {
 	Download(url string) (*domain.WebResource, error)
 }
+
+// ContextDownloader is implemented by downloaders able to abort a download
+// when its context is cancelled or reaches its deadline.
+type ContextDownloader interface {
+	Downloader
+	DownloadContext(ctx context.Context, url string) (*domain.WebResource, error)
+}
+
+// DownloadContext downloads url with the given downloader, passing ctx down
+// when the downloader is a ContextDownloader. Other downloaders are only
+// prevented from starting once ctx is done.
+func DownloadContext(ctx context.Context, downloader Downloader, url string) (*domain.WebResource, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if contextDownloader, ok := downloader.(ContextDownloader); ok {
+		return contextDownloader.DownloadContext(ctx, url)
+	}
+
+	return downloader.Download(url)
+}
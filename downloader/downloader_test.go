@@ -0,0 +1,40 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lauevrar77/dyzone/domain"
+	"github.com/lauevrar77/dyzone/mocks"
+)
+
+func TestDownloadContextPlainDownloader(t *testing.T) {
+	downloads := 0
+	downloader := mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		downloads++
+		return domain.NewWebResource(url, "text/html", []byte("Hello, World!"))
+	})
+
+	webResource, err := DownloadContext(context.Background(), downloader, "https://example.com")
+
+	if err != nil || webResource == nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = DownloadContext(ctx, downloader, "https://example.com")
+
+	if !errors.Is(err, context.Canceled) {
+		t.Logf("Wrong error : %v", err)
+		t.Fail()
+	}
+
+	if downloads != 1 {
+		t.Log("Download should not start once the context is done")
+		t.Fail()
+	}
+}
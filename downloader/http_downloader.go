@@ -1,83 +1,154 @@
 package downloader
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
-	"net/url"
+	"time"
 
 	"github.com/lauevrar77/dyzone/domain"
 )
 
+// HttpClient sends the requests of the HTTP downloader, *http.Client
+// implements it.
 type HttpClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// HttpGetter is the client interface accepted before HttpClient, for
+// clients only able to GET a url. See ChangeHttpGetter.
+type HttpGetter interface {
 	Get(string) (*http.Response, error)
 }
 
+// ErrResponseTooLarge is returned for bodies larger than the size set with
+// WithMaxResponseSize.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// StatusError is returned when the server answers with a 4xx or 5xx status.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+}
+
+func (err *StatusError) Error() string {
+	return fmt.Sprintf(
+		"Error performing HTTP Get to %s. HTTP code is %s.",
+		err.URL,
+		err.Status,
+	)
+}
+
 type httpDownloader struct {
-	httpClient HttpClient
+	httpClient      HttpClient
+	userAgent       string
+	headers         http.Header
+	maxResponseSize int64
 }
 
-func NewHttpDownloader() httpDownloader {
-	client := &http.Client{}
+// NewHttpDownloader builds a downloader fetching urls with HTTP GET requests.
+// Without options, requests time out after DefaultRequestTimeout and are
+// sent with the DefaultUserAgent.
+// Timeouts, TLS and connection pool options apply to the client built here
+// and are lost when it is replaced with ChangeHttpClient.
+func NewHttpDownloader(options ...HttpDownloaderOption) httpDownloader {
+	config := newHttpDownloaderConfig(options)
+
 	return httpDownloader{
-		httpClient: client,
+		httpClient:      config.httpClient(),
+		userAgent:       config.userAgent,
+		headers:         config.headers,
+		maxResponseSize: config.maxResponseSize,
 	}
 }
 
 func (downloader httpDownloader) Download(url string) (*domain.WebResource, error) {
-	response, err := downloader.httpClient.Get(url)
+	return downloader.DownloadContext(context.Background(), url)
+}
+
+func (downloader httpDownloader) DownloadContext(ctx context.Context, url string) (*domain.WebResource, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if downloader.requestFailed(response) {
-		errorMessage := downloader.formatRequestError(url, response)
-		return nil, errors.New(errorMessage)
+	for key, values := range downloader.headers {
+		request.Header[key] = values
+	}
+	if downloader.userAgent != "" {
+		request.Header.Set("User-Agent", downloader.userAgent)
 	}
 
-	webResource, err := downloader.responseToWebResource(url, response)
+	start := time.Now()
+	response, err := downloader.httpClient.Do(request)
 
+	if err != nil {
+		return nil, err
+	}
 	defer response.Body.Close()
-	return webResource, nil
+
+	if downloader.requestFailed(response) {
+		return nil, downloader.requestError(url, response)
+	}
+
+	return downloader.responseToWebResource(url, response, start)
 }
 
 func (downloader *httpDownloader) ChangeHttpClient(client HttpClient) {
 	downloader.httpClient = client
 }
 
-func (downloader httpDownloader) requestFailed(response *http.Response) bool {
-	return response.StatusCode >= 400
+// ChangeHttpGetter replaces the client with one only able to GET urls.
+// Headers, the user agent and the context of the requests are not passed
+// to getter.
+func (downloader *httpDownloader) ChangeHttpGetter(getter HttpGetter) {
+	downloader.httpClient = getterClient{getter: getter}
 }
 
-func (downloader httpDownloader) formatRequestError(url string, response *http.Response) string {
-	return fmt.Sprintf(
-		"Error performing HTTP Get to %s. HTTP code is %s.",
-		url,
-		response.Status,
-	)
+// getterClient adapts an HttpGetter to the HttpClient interface.
+type getterClient struct {
+	getter HttpGetter
 }
 
-func (downloader httpDownloader) responseToWebResource(requestUrl string, response *http.Response) (*domain.WebResource, error) {
-	responseUrl, err := response.Location()
+func (client getterClient) Do(request *http.Request) (*http.Response, error) {
+	return client.getter.Get(request.URL.String())
+}
 
-	if err != nil {
-		responseUrl, err = url.Parse(requestUrl)
+func (downloader httpDownloader) requestFailed(response *http.Response) bool {
+	return response.StatusCode >= 400
+}
+
+func (downloader httpDownloader) requestError(url string, response *http.Response) error {
+	return &StatusError{
+		URL:        url,
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Header:     response.Header.Clone(),
 	}
+}
 
-	if err != nil {
-		return nil, err
+func (downloader httpDownloader) responseToWebResource(requestUrl string, response *http.Response, start time.Time) (*domain.WebResource, error) {
+	// The request of the response is the last one sent by the client,
+	// after following every redirection.
+	finalUrl := requestUrl
+	if response.Request != nil && response.Request.URL != nil {
+		finalUrl = response.Request.URL.String()
 	}
 
-	body, err := ioutil.ReadAll(response.Body)
+	body, err := downloader.readBody(response)
 
 	if err != nil {
 		return nil, err
 	}
 
 	webResource, err := domain.NewWebResource(
-		responseUrl.String(),
+		finalUrl,
 		response.Header.Get("Content-Type"),
 		body,
 	)
@@ -86,5 +157,46 @@ func (downloader httpDownloader) responseToWebResource(requestUrl string, respon
 		return nil, err
 	}
 
+	contentLength := response.ContentLength
+	if contentLength < 0 {
+		contentLength = int64(len(body))
+	}
+
+	webResource.ChangeResponseMetadata(domain.ResponseMetadata{
+		RequestURL:    requestUrl,
+		StatusCode:    response.StatusCode,
+		Status:        response.Status,
+		Proto:         response.Proto,
+		Header:        response.Header,
+		FetchedAt:     start,
+		Duration:      time.Since(start),
+		ContentLength: contentLength,
+		Redirects:     redirectChain(response),
+	})
+
 	return webResource, nil
 }
+
+func (downloader httpDownloader) readBody(response *http.Response) ([]byte, error) {
+	if downloader.maxResponseSize <= 0 {
+		return ioutil.ReadAll(response.Body)
+	}
+
+	if response.ContentLength > downloader.maxResponseSize {
+		return nil, ErrResponseTooLarge
+	}
+
+	// Read one byte more than allowed to detect bodies without a
+	// Content-Length going over the limit.
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, downloader.maxResponseSize+1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > downloader.maxResponseSize {
+		return nil, ErrResponseTooLarge
+	}
+
+	return body, nil
+}
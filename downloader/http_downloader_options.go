@@ -0,0 +1,156 @@
+package downloader
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultRequestTimeout      = 30 * time.Second
+	DefaultConnectTimeout      = 10 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+	DefaultUserAgent           = "dyzone"
+	DefaultMaxRedirects        = 10
+)
+
+// HttpDownloaderOption configures the downloader built by NewHttpDownloader.
+type HttpDownloaderOption func(config *httpDownloaderConfig)
+
+type httpDownloaderConfig struct {
+	requestTimeout      time.Duration
+	connectTimeout      time.Duration
+	tlsHandshakeTimeout time.Duration
+	tlsConfig           *tls.Config
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	maxConnsPerHost     int
+	maxRedirects        int
+	sameDomainRedirects bool
+
+	userAgent       string
+	headers         http.Header
+	maxResponseSize int64
+}
+
+// WithRequestTimeout bounds the whole request, from connection to the end of
+// the body. 0 means no timeout.
+func WithRequestTimeout(timeout time.Duration) HttpDownloaderOption {
+	return func(config *httpDownloaderConfig) {
+		config.requestTimeout = timeout
+	}
+}
+
+// WithConnectTimeout bounds the establishment of TCP connections.
+func WithConnectTimeout(timeout time.Duration) HttpDownloaderOption {
+	return func(config *httpDownloaderConfig) {
+		config.connectTimeout = timeout
+	}
+}
+
+// WithTLSHandshakeTimeout bounds TLS handshakes.
+func WithTLSHandshakeTimeout(timeout time.Duration) HttpDownloaderOption {
+	return func(config *httpDownloaderConfig) {
+		config.tlsHandshakeTimeout = timeout
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used for https urls.
+func WithTLSConfig(tlsConfig *tls.Config) HttpDownloaderOption {
+	return func(config *httpDownloaderConfig) {
+		config.tlsConfig = tlsConfig
+	}
+}
+
+// WithConnectionPool sizes the pool of connections of the transport.
+// maxIdle and maxIdlePerHost bound the connections kept open for reuse,
+// maxPerHost bounds all connections to a host. 0 keeps the Go defaults.
+func WithConnectionPool(maxIdle int, maxIdlePerHost int, maxPerHost int) HttpDownloaderOption {
+	return func(config *httpDownloaderConfig) {
+		config.maxIdleConns = maxIdle
+		config.maxIdleConnsPerHost = maxIdlePerHost
+		config.maxConnsPerHost = maxPerHost
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request.
+func WithUserAgent(userAgent string) HttpDownloaderOption {
+	return func(config *httpDownloaderConfig) {
+		config.userAgent = userAgent
+	}
+}
+
+// WithHeader adds a header sent with every request.
+func WithHeader(key string, value string) HttpDownloaderOption {
+	return func(config *httpDownloaderConfig) {
+		config.headers.Add(key, value)
+	}
+}
+
+// WithMaxResponseSize makes downloads of bodies larger than size bytes fail
+// with ErrResponseTooLarge. 0 means no limit.
+func WithMaxResponseSize(size int64) HttpDownloaderOption {
+	return func(config *httpDownloaderConfig) {
+		config.maxResponseSize = size
+	}
+}
+
+// WithMaxRedirects sets how many redirections a download may follow before
+// failing with ErrTooManyRedirects. 0 refuses every redirection.
+func WithMaxRedirects(redirects int) HttpDownloaderOption {
+	return func(config *httpDownloaderConfig) {
+		config.maxRedirects = redirects
+	}
+}
+
+// WithSameDomainRedirects makes redirections to another domain than the one
+// of the requested url fail with a *CrossDomainRedirectError.
+func WithSameDomainRedirects() HttpDownloaderOption {
+	return func(config *httpDownloaderConfig) {
+		config.sameDomainRedirects = true
+	}
+}
+
+func newHttpDownloaderConfig(options []HttpDownloaderOption) httpDownloaderConfig {
+	config := httpDownloaderConfig{
+		requestTimeout:      DefaultRequestTimeout,
+		connectTimeout:      DefaultConnectTimeout,
+		tlsHandshakeTimeout: DefaultTLSHandshakeTimeout,
+		maxRedirects:        DefaultMaxRedirects,
+		userAgent:           DefaultUserAgent,
+		headers:             make(http.Header),
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	return config
+}
+
+func (config httpDownloaderConfig) httpClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   config.connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = config.tlsHandshakeTimeout
+	transport.TLSClientConfig = config.tlsConfig
+
+	if config.maxIdleConns > 0 {
+		transport.MaxIdleConns = config.maxIdleConns
+	}
+	if config.maxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = config.maxIdleConnsPerHost
+	}
+	if config.maxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = config.maxConnsPerHost
+	}
+
+	return &http.Client{
+		Transport:     transport,
+		Timeout:       config.requestTimeout,
+		CheckRedirect: config.checkRedirect,
+	}
+}
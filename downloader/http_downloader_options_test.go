@@ -0,0 +1,134 @@
+package downloader
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHttpDownloaderHeaders(t *testing.T) {
+	var userAgent, language string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		userAgent = request.Header.Get("User-Agent")
+		language = request.Header.Get("Accept-Language")
+		writer.Write([]byte("Hello, World!"))
+	}))
+	defer server.Close()
+
+	downloader := NewHttpDownloader()
+	downloader.Download(server.URL)
+
+	if userAgent != DefaultUserAgent {
+		t.Logf("Wrong default user agent %s", userAgent)
+		t.Fail()
+	}
+
+	downloader = NewHttpDownloader(WithUserAgent("dyzone-test/1.0"), WithHeader("Accept-Language", "fr-BE"))
+	_, err := downloader.Download(server.URL)
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if userAgent != "dyzone-test/1.0" {
+		t.Logf("Wrong user agent %s", userAgent)
+		t.Fail()
+	}
+
+	if language != "fr-BE" {
+		t.Logf("Wrong header %s", language)
+		t.Fail()
+	}
+}
+
+func TestHttpDownloaderRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	downloader := NewHttpDownloader(WithRequestTimeout(20 * time.Millisecond))
+
+	start := time.Now()
+	_, err := downloader.Download(server.URL)
+
+	if err == nil {
+		t.Log("Stuck request should time out")
+		t.Fail()
+	}
+
+	if time.Since(start) > 500*time.Millisecond {
+		t.Log("Request timeout was not applied")
+		t.Fail()
+	}
+}
+
+func TestHttpDownloaderMaxResponseSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/chunked" {
+			// Flushing before writing the body prevents the
+			// Content-Length from being set.
+			writer.(http.Flusher).Flush()
+		}
+		writer.Write([]byte(strings.Repeat("a", 100)))
+	}))
+	defer server.Close()
+
+	downloader := NewHttpDownloader(WithMaxResponseSize(50))
+
+	for _, path := range []string{"/", "/chunked"} {
+		_, err := downloader.Download(server.URL + path)
+
+		if !errors.Is(err, ErrResponseTooLarge) {
+			t.Logf("Wrong error for %s : %v", path, err)
+			t.Fail()
+		}
+	}
+
+	downloader = NewHttpDownloader(WithMaxResponseSize(100))
+	webResource, err := downloader.Download(server.URL)
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if len(webResource.RawContent()) != 100 {
+		t.Log("Wrong content")
+		t.Fail()
+	}
+}
+
+func TestHttpDownloaderTransport(t *testing.T) {
+	config := newHttpDownloaderConfig([]HttpDownloaderOption{
+		WithConnectTimeout(time.Second),
+		WithTLSHandshakeTimeout(2 * time.Second),
+		WithConnectionPool(10, 2, 4),
+	})
+	client := config.httpClient()
+	transport := client.Transport.(*http.Transport)
+
+	if transport.TLSHandshakeTimeout != 2*time.Second {
+		t.Log("Wrong TLS handshake timeout")
+		t.Fail()
+	}
+
+	if transport.MaxIdleConns != 10 || transport.MaxIdleConnsPerHost != 2 || transport.MaxConnsPerHost != 4 {
+		t.Log("Wrong connection pool")
+		t.Fail()
+	}
+
+	if client.Timeout != DefaultRequestTimeout {
+		t.Log("Wrong default request timeout")
+		t.Fail()
+	}
+}
@@ -2,11 +2,14 @@ package downloader
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/lauevrar77/dyzone/mocks"
 )
@@ -44,6 +47,26 @@ func TestChangeClient(t *testing.T) {
 	downloader.ChangeHttpClient(mockClient)
 }
 
+func TestChangeHttpGetter(t *testing.T) {
+	mockClient := mocks.NewHttpMockClient(
+		makeGetFunction("text/html", "https://example.com", "Hello, World!", 200),
+	)
+	downloader := NewHttpDownloader()
+	downloader.ChangeHttpGetter(mockClient)
+
+	webResponse, err := downloader.Download("https://example.com")
+
+	if err != nil || webResponse == nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if !sameContent(webResponse.RawContent(), []byte("Hello, World!")) {
+		t.Log("Wrong content")
+		t.Fail()
+	}
+}
+
 func TestDownload(t *testing.T) {
 	mockClient := mocks.NewHttpMockClient(
 		makeGetFunction("text/html", "https://example.com", "Hello, World!", 200),
@@ -94,6 +117,27 @@ func TestDownloadHttpError(t *testing.T) {
 	}
 }
 
+func TestDownloadStatusError(t *testing.T) {
+	mockClient := mocks.NewHttpMockClient(
+		makeGetFunction("text/html", "https://example.com", "Slow down", 429),
+	)
+	downloader := NewHttpDownloader()
+	downloader.ChangeHttpClient(mockClient)
+
+	_, err := downloader.Download("https://example.com")
+
+	var statusError *StatusError
+	if !errors.As(err, &statusError) {
+		t.Logf("Wrong error : %v", err)
+		t.FailNow()
+	}
+
+	if statusError.StatusCode != 429 || statusError.URL != "https://example.com" {
+		t.Logf("Wrong status error : %v", statusError)
+		t.Fail()
+	}
+}
+
 func TestDownloadExceptionError(t *testing.T) {
 	mockClient := mocks.NewHttpMockClient(
 		makeGetExceptionFunction(),
@@ -108,6 +152,81 @@ func TestDownloadExceptionError(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestDownloadContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "text/html")
+		writer.Write([]byte("Hello, World!"))
+	}))
+	defer server.Close()
+
+	downloader := NewHttpDownloader()
+
+	webResource, err := downloader.DownloadContext(context.Background(), server.URL)
+
+	if err != nil || webResource == nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = downloader.DownloadContext(ctx, server.URL)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Logf("Wrong error : %v", err)
+		t.Fail()
+	}
+}
+
+func TestDownloadResponseMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "text/html")
+		writer.Header().Set("ETag", `"v1"`)
+		writer.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+		writer.Header().Set("Content-Language", "fr")
+		writer.Write([]byte("Hello, World!"))
+	}))
+	defer server.Close()
+
+	downloader := NewHttpDownloader()
+
+	before := time.Now()
+	webResource, err := downloader.Download(server.URL + "/page")
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if webResource.StatusCode() != 200 || webResource.ResponseMetadata().Proto != "HTTP/1.1" {
+		t.Log("Wrong status")
+		t.Fail()
+	}
+
+	header := webResource.Header()
+	if header.Get("ETag") != `"v1"` || header.Get("Content-Language") != "fr" || header.Get("Last-Modified") == "" {
+		t.Logf("Wrong headers %v", header)
+		t.Fail()
+	}
+
+	if webResource.RequestURL() != server.URL+"/page" {
+		t.Logf("Wrong request url %s", webResource.RequestURL())
+		t.Fail()
+	}
+
+	if webResource.ContentLength() != 13 {
+		t.Logf("Wrong content length %d", webResource.ContentLength())
+		t.Fail()
+	}
+
+	if webResource.FetchedAt().Before(before) || webResource.DownloadDuration() <= 0 {
+		t.Log("Wrong timing")
+		t.Fail()
+	}
+}
+
 func sameContent(content1 []byte, content2 []byte) bool {
 	if len(content1) != len(content2) {
 		return false
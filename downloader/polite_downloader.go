@@ -0,0 +1,173 @@
+package downloader
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/lauevrar77/dyzone/domain"
+)
+
+// CrawlDelayer is implemented by downloaders knowing the delay a host asks
+// between two requests, such as the one returned by NewRobotsDownloader.
+type CrawlDelayer interface {
+	CrawlDelay(ctx context.Context, url string) (time.Duration, bool)
+}
+
+// PolitenessOption configures a downloader returned by NewPoliteDownloader.
+type PolitenessOption func(downloader *politeDownloader)
+
+// WithRequestsPerSecond limits the number of requests started per second
+// on each host.
+func WithRequestsPerSecond(requests float64) PolitenessOption {
+	return func(downloader *politeDownloader) {
+		if requests > 0 {
+			downloader.requestInterval = time.Duration(float64(time.Second) / requests)
+		}
+	}
+}
+
+// WithMinDelay sets the minimum delay between the start of two requests on
+// the same host.
+func WithMinDelay(delay time.Duration) PolitenessOption {
+	return func(downloader *politeDownloader) {
+		downloader.minDelay = delay
+	}
+}
+
+// WithMaxHostConcurrency limits how many requests may run at the same time
+// on a single host. A value lower than 1 means no limit.
+func WithMaxHostConcurrency(requests int) PolitenessOption {
+	return func(downloader *politeDownloader) {
+		downloader.maxConcurrency = requests
+	}
+}
+
+type politeDownloader struct {
+	downloader      Downloader
+	requestInterval time.Duration
+	minDelay        time.Duration
+	maxConcurrency  int
+
+	lock  sync.Mutex
+	hosts map[string]*hostSchedule
+}
+
+type hostSchedule struct {
+	lock      sync.Mutex
+	nextStart time.Time
+	slots     chan struct{}
+}
+
+// NewPoliteDownloader wraps downloader so that requests to a same host are
+// spaced and capped according to options, while requests to different
+// hosts do not wait for each other. When downloader, or a downloader it
+// wraps, is a CrawlDelayer, a longer robots.txt Crawl-delay is honored.
+func NewPoliteDownloader(downloader Downloader, options ...PolitenessOption) *politeDownloader {
+	polite := &politeDownloader{
+		downloader: downloader,
+		hosts:      make(map[string]*hostSchedule),
+	}
+
+	for _, option := range options {
+		option(polite)
+	}
+
+	return polite
+}
+
+func (downloader *politeDownloader) Download(url string) (*domain.WebResource, error) {
+	return downloader.DownloadContext(context.Background(), url)
+}
+
+func (downloader *politeDownloader) DownloadContext(ctx context.Context, rawUrl string) (*domain.WebResource, error) {
+	parsedUrl, err := url.Parse(rawUrl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := downloader.schedule(parsedUrl.Host)
+
+	if schedule.slots != nil {
+		select {
+		case schedule.slots <- struct{}{}:
+			defer func() { <-schedule.slots }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	start := schedule.reserve(time.Now(), downloader.delay(ctx, rawUrl))
+	if wait := time.Until(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return DownloadContext(ctx, downloader.downloader, rawUrl)
+}
+
+func (downloader *politeDownloader) schedule(host string) *hostSchedule {
+	downloader.lock.Lock()
+	defer downloader.lock.Unlock()
+
+	schedule, found := downloader.hosts[host]
+	if !found {
+		schedule = &hostSchedule{}
+		if downloader.maxConcurrency > 0 {
+			schedule.slots = make(chan struct{}, downloader.maxConcurrency)
+		}
+		downloader.hosts[host] = schedule
+	}
+
+	return schedule
+}
+
+// delay returns the longest of the configured delays and of the crawl delay
+// of the host of rawUrl.
+func (downloader *politeDownloader) delay(ctx context.Context, rawUrl string) time.Duration {
+	delay := downloader.minDelay
+	if downloader.requestInterval > delay {
+		delay = downloader.requestInterval
+	}
+
+	if crawlDelayer, ok := downloader.downloader.(CrawlDelayer); ok {
+		if crawlDelay, found := crawlDelayer.CrawlDelay(ctx, rawUrl); found && crawlDelay > delay {
+			delay = crawlDelay
+		}
+	}
+
+	return delay
+}
+
+// reserve books the next start time of the host, at least delay after the
+// previously booked one, and returns it.
+func (schedule *hostSchedule) reserve(now time.Time, delay time.Duration) time.Time {
+	schedule.lock.Lock()
+	defer schedule.lock.Unlock()
+
+	start := now
+	if schedule.nextStart.After(start) {
+		start = schedule.nextStart
+	}
+	schedule.nextStart = start.Add(delay)
+
+	return start
+}
+
+// CrawlDelay passes the crawl delay of the wrapped downloader through, so
+// that politeness still applies when further decorators are stacked.
+func (downloader *politeDownloader) CrawlDelay(ctx context.Context, url string) (time.Duration, bool) {
+	if crawlDelayer, ok := downloader.downloader.(CrawlDelayer); ok {
+		return crawlDelayer.CrawlDelay(ctx, url)
+	}
+
+	return 0, false
+}
@@ -0,0 +1,145 @@
+package downloader
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lauevrar77/dyzone/domain"
+	"github.com/lauevrar77/dyzone/mocks"
+)
+
+func workingDownloaderMock() mocks.DownloaderMock {
+	return mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		return domain.NewWebResource(url, "text/html", []byte("Hello, World!"))
+	})
+}
+
+func downloadAll(downloader Downloader, urls []string) {
+	var group sync.WaitGroup
+	for _, url := range urls {
+		group.Add(1)
+		go func(url string) {
+			defer group.Done()
+			downloader.Download(url)
+		}(url)
+	}
+	group.Wait()
+}
+
+func TestPoliteDownloaderMinDelay(t *testing.T) {
+	downloader := NewPoliteDownloader(workingDownloaderMock(), WithMinDelay(20*time.Millisecond))
+
+	start := time.Now()
+	downloadAll(downloader, []string{"https://example.com/1", "https://example.com/2", "https://example.com/3"})
+	elapsed := time.Since(start)
+
+	if elapsed < 40*time.Millisecond {
+		t.Logf("Requests to a same host were not spaced : %s", elapsed)
+		t.Fail()
+	}
+}
+
+func TestPoliteDownloaderRequestsPerSecond(t *testing.T) {
+	downloader := NewPoliteDownloader(workingDownloaderMock(), WithRequestsPerSecond(50))
+
+	start := time.Now()
+	downloadAll(downloader, []string{"https://example.com/1", "https://example.com/2", "https://example.com/3"})
+	elapsed := time.Since(start)
+
+	if elapsed < 40*time.Millisecond {
+		t.Logf("Requests to a same host were not rate limited : %s", elapsed)
+		t.Fail()
+	}
+}
+
+func TestPoliteDownloaderDifferentHosts(t *testing.T) {
+	downloader := NewPoliteDownloader(workingDownloaderMock(), WithMinDelay(time.Second))
+
+	start := time.Now()
+	downloadAll(downloader, []string{"https://example.com/", "https://example.org/", "https://example.net/"})
+	elapsed := time.Since(start)
+
+	if elapsed > 500*time.Millisecond {
+		t.Logf("Requests to different hosts should not wait : %s", elapsed)
+		t.Fail()
+	}
+}
+
+func TestPoliteDownloaderHostConcurrency(t *testing.T) {
+	var lock sync.Mutex
+	running := make(map[string]int)
+	maxRunning := make(map[string]int)
+
+	mock := mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		webResource, err := domain.NewWebResource(url, "text/html", []byte("Hello, World!"))
+
+		lock.Lock()
+		running[webResource.Domain()]++
+		if running[webResource.Domain()] > maxRunning[webResource.Domain()] {
+			maxRunning[webResource.Domain()] = running[webResource.Domain()]
+		}
+		lock.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		lock.Lock()
+		running[webResource.Domain()]--
+		lock.Unlock()
+
+		return webResource, err
+	})
+	downloader := NewPoliteDownloader(mock, WithMaxHostConcurrency(2))
+
+	urls := make([]string, 0)
+	for i := 0; i < 6; i++ {
+		urls = append(urls, "https://example.com/", "https://example.org/")
+	}
+	downloadAll(downloader, urls)
+
+	for host, max := range maxRunning {
+		if max > 2 {
+			t.Logf("%d concurrent requests on %s", max, host)
+			t.Fail()
+		}
+	}
+}
+
+func TestPoliteDownloaderCrawlDelay(t *testing.T) {
+	var lock sync.Mutex
+	fetched := make(map[string]int)
+	robots := "User-agent: *\nCrawl-delay: 0.03\n"
+	mock := mocks.NewDownloaderMock(makeRobotsDownloaderFunc(robots, fetched, &lock))
+	downloader := NewPoliteDownloader(NewRobotsDownloader(mock, "dyzone"))
+
+	delay, found := downloader.CrawlDelay(context.Background(), "https://example.com/")
+	if !found || delay != 30*time.Millisecond {
+		t.Logf("Wrong crawl delay %s", delay)
+		t.Fail()
+	}
+
+	start := time.Now()
+	downloadAll(downloader, []string{"https://example.com/1", "https://example.com/2", "https://example.com/3"})
+	elapsed := time.Since(start)
+
+	if elapsed < 60*time.Millisecond {
+		t.Logf("Crawl delay was not honored : %s", elapsed)
+		t.Fail()
+	}
+}
+
+func TestPoliteDownloaderCancelled(t *testing.T) {
+	downloader := NewPoliteDownloader(workingDownloaderMock(), WithMinDelay(time.Second))
+	downloader.Download("https://example.com/")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := downloader.DownloadContext(ctx, "https://example.com/")
+
+	if err != context.DeadlineExceeded {
+		t.Logf("Wrong error : %v", err)
+		t.Fail()
+	}
+}
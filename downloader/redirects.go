@@ -0,0 +1,54 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/lauevrar77/dyzone/domain"
+)
+
+// ErrTooManyRedirects is returned when a download goes through more
+// redirections than allowed by WithMaxRedirects.
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// CrossDomainRedirectError is returned when a redirection leaves the domain
+// of the requested url while WithSameDomainRedirects is set.
+type CrossDomainRedirectError struct {
+	From string
+	To   string
+}
+
+func (err *CrossDomainRedirectError) Error() string {
+	return fmt.Sprintf("refusing cross domain redirect from %s to %s", err.From, err.To)
+}
+
+func (config httpDownloaderConfig) checkRedirect(request *http.Request, via []*http.Request) error {
+	if len(via) > config.maxRedirects {
+		return ErrTooManyRedirects
+	}
+
+	origin := via[0].URL
+	if config.sameDomainRedirects && !strings.EqualFold(origin.Hostname(), request.URL.Hostname()) {
+		return &CrossDomainRedirectError{From: origin.String(), To: request.URL.String()}
+	}
+
+	return nil
+}
+
+// redirectChain rebuilds the redirections that led to response by walking
+// back from its request to the first one.
+func redirectChain(response *http.Response) []domain.RedirectHop {
+	hops := make([]domain.RedirectHop, 0)
+	if response.Request == nil {
+		return hops
+	}
+
+	for redirect := response.Request.Response; redirect != nil && redirect.Request != nil; redirect = redirect.Request.Response {
+		hop := domain.RedirectHop{URL: redirect.Request.URL.String(), StatusCode: redirect.StatusCode}
+		hops = append([]domain.RedirectHop{hop}, hops...)
+	}
+
+	return hops
+}
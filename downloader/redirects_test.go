@@ -0,0 +1,152 @@
+package downloader
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newRedirectingServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(writer http.ResponseWriter, request *http.Request) {
+		http.Redirect(writer, request, "/b", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/b", func(writer http.ResponseWriter, request *http.Request) {
+		http.Redirect(writer, request, "/docs/c", http.StatusFound)
+	})
+	mux.HandleFunc("/docs/c", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "text/html")
+		writer.Write([]byte(`<html><body><a href="page.html"></a></body></html>`))
+	})
+	mux.HandleFunc("/away", func(writer http.ResponseWriter, request *http.Request) {
+		// Same server, reached through another host name
+		target := strings.Replace("http://"+request.Host, "127.0.0.1", "localhost", 1) + "/docs/c"
+		http.Redirect(writer, request, target, http.StatusFound)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestDownloadRedirectChain(t *testing.T) {
+	server := newRedirectingServer()
+	defer server.Close()
+
+	downloader := NewHttpDownloader()
+	webResource, err := downloader.Download(server.URL + "/a")
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if webResource.RequestURL() != server.URL+"/a" {
+		t.Logf("Wrong request url %s", webResource.RequestURL())
+		t.Fail()
+	}
+
+	if webResource.FinalURL() != server.URL+"/docs/c" {
+		t.Logf("Wrong final url %s", webResource.FinalURL())
+		t.Fail()
+	}
+
+	redirects := webResource.Redirects()
+	if len(redirects) != 2 {
+		t.Logf("Wrong redirect chain %v", redirects)
+		t.FailNow()
+	}
+
+	if redirects[0].URL != server.URL+"/a" || redirects[0].StatusCode != http.StatusMovedPermanently {
+		t.Logf("Wrong first hop %v", redirects[0])
+		t.Fail()
+	}
+
+	if redirects[1].URL != server.URL+"/b" || redirects[1].StatusCode != http.StatusFound {
+		t.Logf("Wrong second hop %v", redirects[1])
+		t.Fail()
+	}
+
+	if webResource.URI() != "/docs/c" {
+		t.Logf("Wrong uri %s", webResource.URI())
+		t.Fail()
+	}
+
+	links, err := webResource.LinksUrls()
+	if err != nil || len(links) != 1 || links[0] != server.URL+"/docs/page.html" {
+		t.Logf("Links should be resolved against the final url : %v", links)
+		t.Fail()
+	}
+}
+
+func TestDownloadWithoutRedirect(t *testing.T) {
+	server := newRedirectingServer()
+	defer server.Close()
+
+	downloader := NewHttpDownloader()
+	webResource, err := downloader.Download(server.URL + "/docs/c")
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if len(webResource.Redirects()) != 0 || webResource.FinalURL() != webResource.RequestURL() {
+		t.Log("No redirection should be recorded")
+		t.Fail()
+	}
+}
+
+func TestDownloadMaxRedirects(t *testing.T) {
+	server := newRedirectingServer()
+	defer server.Close()
+
+	downloader := NewHttpDownloader(WithMaxRedirects(1))
+	_, err := downloader.Download(server.URL + "/a")
+
+	if !errors.Is(err, ErrTooManyRedirects) {
+		t.Logf("Wrong error : %v", err)
+		t.Fail()
+	}
+
+	_, err = downloader.Download(server.URL + "/b")
+
+	if err != nil {
+		t.Log(err)
+		t.Fail()
+	}
+}
+
+func TestDownloadSameDomainRedirects(t *testing.T) {
+	server := newRedirectingServer()
+	defer server.Close()
+
+	downloader := NewHttpDownloader()
+	_, err := downloader.Download(server.URL + "/away")
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	downloader = NewHttpDownloader(WithSameDomainRedirects())
+	_, err = downloader.Download(server.URL + "/away")
+
+	var crossDomain *CrossDomainRedirectError
+	if !errors.As(err, &crossDomain) {
+		t.Logf("Wrong error : %v", err)
+		t.FailNow()
+	}
+
+	if crossDomain.From != server.URL+"/away" || !strings.HasPrefix(crossDomain.To, "http://localhost:") {
+		t.Logf("Wrong cross domain error : %v", crossDomain)
+		t.Fail()
+	}
+
+	_, err = downloader.Download(server.URL + "/a")
+
+	if err != nil {
+		t.Log(err)
+		t.Fail()
+	}
+}
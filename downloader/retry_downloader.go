@@ -0,0 +1,236 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lauevrar77/dyzone/domain"
+)
+
+const (
+	DefaultMaxAttempts  = 3
+	DefaultInitialDelay = 500 * time.Millisecond
+	DefaultMaxDelay     = 30 * time.Second
+)
+
+// DefaultRetryableStatusCodes are the statuses retried unless
+// WithRetryableStatusCodes says otherwise.
+var DefaultRetryableStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryOption configures a downloader returned by NewRetryDownloader.
+type RetryOption func(downloader *retryDownloader)
+
+// WithMaxAttempts sets how many times a url is downloaded at most, the first
+// attempt included.
+func WithMaxAttempts(attempts int) RetryOption {
+	return func(downloader *retryDownloader) {
+		if attempts < 1 {
+			attempts = 1
+		}
+		downloader.maxAttempts = attempts
+	}
+}
+
+// WithBackoff sets the delay before the first retry, doubled after each
+// attempt up to maxDelay.
+func WithBackoff(initialDelay time.Duration, maxDelay time.Duration) RetryOption {
+	return func(downloader *retryDownloader) {
+		downloader.initialDelay = initialDelay
+		downloader.maxDelay = maxDelay
+	}
+}
+
+// WithRetryableStatusCodes replaces the statuses worth a retry.
+func WithRetryableStatusCodes(statusCodes ...int) RetryOption {
+	return func(downloader *retryDownloader) {
+		downloader.retryableStatusCodes = make(map[int]bool)
+		for _, statusCode := range statusCodes {
+			downloader.retryableStatusCodes[statusCode] = true
+		}
+	}
+}
+
+// WithRetryableErrors replaces the function telling which errors, other
+// than a *StatusError, are worth a retry. It defaults to IsTransientError.
+func WithRetryableErrors(isRetryable func(err error) bool) RetryOption {
+	return func(downloader *retryDownloader) {
+		downloader.isRetryableError = isRetryable
+	}
+}
+
+type retryDownloader struct {
+	downloader           Downloader
+	maxAttempts          int
+	initialDelay         time.Duration
+	maxDelay             time.Duration
+	retryableStatusCodes map[int]bool
+	isRetryableError     func(err error) bool
+	random               func() float64
+}
+
+// NewRetryDownloader wraps downloader so that failed downloads are tried
+// again after an exponential backoff with jitter. A Retry-After header sent
+// along a *StatusError is honored, unless it asks to wait longer than the
+// maximum delay, in which case the error is returned right away.
+// The number of attempts is recorded on the downloaded resource.
+func NewRetryDownloader(downloader Downloader, options ...RetryOption) *retryDownloader {
+	retry := &retryDownloader{
+		downloader:       downloader,
+		maxAttempts:      DefaultMaxAttempts,
+		initialDelay:     DefaultInitialDelay,
+		maxDelay:         DefaultMaxDelay,
+		isRetryableError: IsTransientError,
+		random:           rand.Float64,
+	}
+	WithRetryableStatusCodes(DefaultRetryableStatusCodes...)(retry)
+
+	for _, option := range options {
+		option(retry)
+	}
+
+	return retry
+}
+
+func (downloader *retryDownloader) Download(url string) (*domain.WebResource, error) {
+	return downloader.DownloadContext(context.Background(), url)
+}
+
+func (downloader *retryDownloader) DownloadContext(ctx context.Context, url string) (*domain.WebResource, error) {
+	for attempt := 1; ; attempt++ {
+		webResource, err := DownloadContext(ctx, downloader.downloader, url)
+
+		if err == nil {
+			webResource.ChangeAttempts(attempt)
+			return webResource, nil
+		}
+
+		if attempt >= downloader.maxAttempts || !downloader.retryable(ctx, err) {
+			return nil, err
+		}
+
+		delay, ok := downloader.delay(attempt, err)
+		if !ok {
+			return nil, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// CrawlDelay passes the crawl delay of the wrapped downloader through.
+func (downloader *retryDownloader) CrawlDelay(ctx context.Context, url string) (time.Duration, bool) {
+	if crawlDelayer, ok := downloader.downloader.(CrawlDelayer); ok {
+		return crawlDelayer.CrawlDelay(ctx, url)
+	}
+
+	return 0, false
+}
+
+func (downloader *retryDownloader) retryable(ctx context.Context, err error) bool {
+	// Only the caller giving up stops the retries, request timeouts match
+	// context.DeadlineExceeded too but are worth retrying.
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var statusError *StatusError
+	if errors.As(err, &statusError) {
+		return downloader.retryableStatusCodes[statusError.StatusCode]
+	}
+
+	return downloader.isRetryableError(err)
+}
+
+// delay returns how long to wait after the given failed attempt, and false
+// when the server asks to wait longer than the maximum delay.
+func (downloader *retryDownloader) delay(attempt int, err error) (time.Duration, bool) {
+	backoff := downloader.initialDelay
+	for i := 1; i < attempt && backoff < downloader.maxDelay; i++ {
+		backoff *= 2
+	}
+	if backoff > downloader.maxDelay {
+		backoff = downloader.maxDelay
+	}
+
+	// Equal jitter : wait at least half of the backoff so that retries
+	// keep slowing down while spreading concurrent clients.
+	delay := backoff/2 + time.Duration(downloader.random()*float64(backoff/2))
+
+	var statusError *StatusError
+	if errors.As(err, &statusError) && statusError.Header != nil {
+		if retryAfter, found := ParseRetryAfter(statusError.Header.Get("Retry-After"), time.Now()); found {
+			if retryAfter > downloader.maxDelay {
+				return 0, false
+			}
+			if retryAfter > delay {
+				delay = retryAfter
+			}
+		}
+	}
+
+	return delay, true
+}
+
+// ParseRetryAfter reads a Retry-After header value, given either as a number
+// of seconds or as an HTTP date, and returns how long to wait from now.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	wait := date.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+
+	return wait, true
+}
+
+// IsTransientError reports whether err is a network failure likely to go
+// away on a new attempt : timeouts, refused or reset connections and
+// connections closed in the middle of a response.
+func IsTransientError(err error) bool {
+	var netError net.Error
+	if errors.As(err, &netError) && netError.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
@@ -0,0 +1,258 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lauevrar77/dyzone/domain"
+	"github.com/lauevrar77/dyzone/mocks"
+)
+
+func makeFlakyDownloaderFunc(failures int, failure error, attempts *int, lock *sync.Mutex) func(url string) (*domain.WebResource, error) {
+	return func(url string) (*domain.WebResource, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		*attempts++
+		if *attempts <= failures {
+			return nil, failure
+		}
+
+		return domain.NewWebResource(url, "text/html", []byte("Hello, World!"))
+	}
+}
+
+func makeStatusError(statusCode int, retryAfter string) *StatusError {
+	header := make(http.Header)
+	if retryAfter != "" {
+		header.Set("Retry-After", retryAfter)
+	}
+
+	return &StatusError{
+		URL:        "https://example.com",
+		StatusCode: statusCode,
+		Status:     http.StatusText(statusCode),
+		Header:     header,
+	}
+}
+
+func TestRetryDownloader(t *testing.T) {
+	var lock sync.Mutex
+	attempts := 0
+	mock := mocks.NewDownloaderMock(makeFlakyDownloaderFunc(2, makeStatusError(503, ""), &attempts, &lock))
+	downloader := NewRetryDownloader(mock, WithBackoff(time.Millisecond, 10*time.Millisecond))
+
+	webResource, err := downloader.Download("https://example.com")
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if webResource.Attempts() != 3 {
+		t.Logf("Wrong number of attempts recorded : %d", webResource.Attempts())
+		t.Fail()
+	}
+}
+
+func TestRetryDownloaderMaxAttempts(t *testing.T) {
+	var lock sync.Mutex
+	attempts := 0
+	mock := mocks.NewDownloaderMock(makeFlakyDownloaderFunc(5, makeStatusError(429, ""), &attempts, &lock))
+	downloader := NewRetryDownloader(mock, WithMaxAttempts(4), WithBackoff(time.Millisecond, 10*time.Millisecond))
+
+	_, err := downloader.Download("https://example.com")
+
+	var statusError *StatusError
+	if !errors.As(err, &statusError) || statusError.StatusCode != 429 {
+		t.Logf("Wrong error : %v", err)
+		t.Fail()
+	}
+
+	if attempts != 4 {
+		t.Logf("Wrong number of attempts : %d", attempts)
+		t.Fail()
+	}
+}
+
+func TestRetryDownloaderNotRetryable(t *testing.T) {
+	var lock sync.Mutex
+	attempts := 0
+	mock := mocks.NewDownloaderMock(makeFlakyDownloaderFunc(1, makeStatusError(404, ""), &attempts, &lock))
+	downloader := NewRetryDownloader(mock, WithBackoff(time.Millisecond, 10*time.Millisecond))
+
+	_, err := downloader.Download("https://example.com")
+
+	if err == nil || attempts != 1 {
+		t.Logf("404 should not be retried : %d attempts", attempts)
+		t.Fail()
+	}
+
+	attempts = 0
+	mock = mocks.NewDownloaderMock(makeFlakyDownloaderFunc(1, makeStatusError(404, ""), &attempts, &lock))
+	downloader = NewRetryDownloader(mock, WithBackoff(time.Millisecond, 10*time.Millisecond), WithRetryableStatusCodes(404))
+
+	_, err = downloader.Download("https://example.com")
+
+	if err != nil || attempts != 2 {
+		t.Logf("404 should be retried : %d attempts", attempts)
+		t.Fail()
+	}
+}
+
+func TestRetryDownloaderErrorClasses(t *testing.T) {
+	var lock sync.Mutex
+	attempts := 0
+	mock := mocks.NewDownloaderMock(makeFlakyDownloaderFunc(1, syscall.ECONNRESET, &attempts, &lock))
+	downloader := NewRetryDownloader(mock, WithBackoff(time.Millisecond, 10*time.Millisecond))
+
+	_, err := downloader.Download("https://example.com")
+
+	if err != nil || attempts != 2 {
+		t.Logf("Reset connection should be retried : %d attempts", attempts)
+		t.Fail()
+	}
+
+	attempts = 0
+	mock = mocks.NewDownloaderMock(makeFlakyDownloaderFunc(1, errors.New("unsupported protocol scheme"), &attempts, &lock))
+	downloader = NewRetryDownloader(mock, WithBackoff(time.Millisecond, 10*time.Millisecond))
+
+	_, err = downloader.Download("https://example.com")
+
+	if err == nil || attempts != 1 {
+		t.Logf("Unknown error should not be retried : %d attempts", attempts)
+		t.Fail()
+	}
+
+	attempts = 0
+	mock = mocks.NewDownloaderMock(makeFlakyDownloaderFunc(1, errors.New("unsupported protocol scheme"), &attempts, &lock))
+	downloader = NewRetryDownloader(mock, WithBackoff(time.Millisecond, 10*time.Millisecond), WithRetryableErrors(func(err error) bool {
+		return true
+	}))
+
+	_, err = downloader.Download("https://example.com")
+
+	if err != nil || attempts != 2 {
+		t.Logf("Custom error class should be retried : %d attempts", attempts)
+		t.Fail()
+	}
+
+	attempts = 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		lock.Lock()
+		attempts++
+		slow := attempts == 1
+		lock.Unlock()
+
+		if slow {
+			time.Sleep(200 * time.Millisecond)
+		}
+		writer.Write([]byte("Hello, World!"))
+	}))
+	defer server.Close()
+
+	downloader = NewRetryDownloader(NewHttpDownloader(WithRequestTimeout(50*time.Millisecond)), WithBackoff(time.Millisecond, 10*time.Millisecond))
+
+	_, err = downloader.Download(server.URL)
+
+	if err != nil || attempts != 2 {
+		t.Logf("Request timeout should be retried : %d attempts, %v", attempts, err)
+		t.Fail()
+	}
+}
+
+func TestRetryDownloaderRetryAfterTooLong(t *testing.T) {
+	var lock sync.Mutex
+	attempts := 0
+	mock := mocks.NewDownloaderMock(makeFlakyDownloaderFunc(1, makeStatusError(503, "3600"), &attempts, &lock))
+	downloader := NewRetryDownloader(mock, WithBackoff(time.Millisecond, time.Second))
+
+	start := time.Now()
+	_, err := downloader.Download("https://example.com")
+
+	if err == nil || attempts != 1 {
+		t.Logf("Retry-After longer than the max delay should give up : %d attempts", attempts)
+		t.Fail()
+	}
+
+	if time.Since(start) > 500*time.Millisecond {
+		t.Log("Retry downloader should not wait")
+		t.Fail()
+	}
+}
+
+func TestRetryDownloaderCancelled(t *testing.T) {
+	var lock sync.Mutex
+	attempts := 0
+	mock := mocks.NewDownloaderMock(makeFlakyDownloaderFunc(5, makeStatusError(503, ""), &attempts, &lock))
+	downloader := NewRetryDownloader(mock, WithBackoff(time.Second, time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := downloader.DownloadContext(ctx, "https://example.com")
+
+	if err != context.DeadlineExceeded {
+		t.Logf("Wrong error : %v", err)
+		t.Fail()
+	}
+}
+
+func TestRetryDownloaderDelay(t *testing.T) {
+	downloader := NewRetryDownloader(workingDownloaderMock(), WithBackoff(100*time.Millisecond, time.Second))
+	downloader.random = func() float64 { return 1 }
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for index, expect := range expected {
+		delay, _ := downloader.delay(index+1, errors.New("error"))
+		if delay != expect {
+			t.Logf("Attempt %d : %s different of %s", index+1, delay, expect)
+			t.Fail()
+		}
+	}
+
+	downloader.random = func() float64 { return 0 }
+	delay, _ := downloader.delay(1, errors.New("error"))
+	if delay != 50*time.Millisecond {
+		t.Logf("Jitter should keep half of the backoff : %s", delay)
+		t.Fail()
+	}
+
+	delay, ok := downloader.delay(1, makeStatusError(429, "1"))
+	if !ok || delay != time.Second {
+		t.Logf("Retry-After should be honored : %s", delay)
+		t.Fail()
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2015, time.October, 21, 7, 28, 0, 0, time.UTC)
+
+	cases := []struct {
+		value    string
+		expected time.Duration
+		found    bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{" 0 ", 0, true},
+		{"Wed, 21 Oct 2015 07:30:00 GMT", 2 * time.Minute, true},
+		{"Wed, 21 Oct 2015 07:00:00 GMT", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+
+	for _, c := range cases {
+		wait, found := ParseRetryAfter(c.value, now)
+		if wait != c.expected || found != c.found {
+			t.Logf("%q : got %s %t, expected %s %t", c.value, wait, found, c.expected, c.found)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package downloader
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRobotsSize is the amount of a robots.txt file that is parsed, content
+// after it is ignored as allowed by RFC 9309.
+const maxRobotsSize = 500 * 1024
+
+// RobotsRules holds the directives of a robots.txt file.
+// A zero RobotsRules allows everything.
+type RobotsRules struct {
+	groups   []robotsGroup
+	sitemaps []string
+}
+
+type robotsGroup struct {
+	userAgents    []string
+	rules         []robotsRule
+	crawlDelay    time.Duration
+	hasCrawlDelay bool
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// ParseRobots reads the User-agent, Allow, Disallow, Crawl-delay and Sitemap
+// directives of a robots.txt file. Unknown directives and malformed lines
+// are ignored.
+func ParseRobots(content []byte) *RobotsRules {
+	if len(content) > maxRobotsSize {
+		content = content[:maxRobotsSize]
+	}
+
+	rules := &RobotsRules{}
+	var group *robotsGroup
+	groupHasDirectives := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRobotsSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+
+		separator := strings.Index(line, ":")
+		if separator < 0 {
+			continue
+		}
+
+		key := strings.ToLower(strings.TrimSpace(line[:separator]))
+		value := strings.TrimSpace(line[separator+1:])
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share the same group
+			if group == nil || groupHasDirectives {
+				rules.groups = append(rules.groups, robotsGroup{})
+				group = &rules.groups[len(rules.groups)-1]
+				groupHasDirectives = false
+			}
+			group.userAgents = append(group.userAgents, strings.ToLower(value))
+		case "allow", "disallow":
+			if group == nil {
+				continue
+			}
+			groupHasDirectives = true
+
+			// An empty Disallow allows everything, which is the default
+			if value == "" {
+				continue
+			}
+			group.rules = append(group.rules, robotsRule{allow: key == "allow", pattern: value})
+		case "crawl-delay":
+			if group == nil {
+				continue
+			}
+			groupHasDirectives = true
+
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds < 0 {
+				continue
+			}
+			group.crawlDelay = time.Duration(seconds * float64(time.Second))
+			group.hasCrawlDelay = true
+		case "sitemap":
+			if value != "" {
+				rules.sitemaps = append(rules.sitemaps, value)
+			}
+		}
+	}
+
+	return rules
+}
+
+// Allowed reports whether the crawler identified by userAgent may fetch
+// path. path is the request URI, query string included. The longest
+// matching pattern wins, Allow winning ties.
+func (rules *RobotsRules) Allowed(userAgent string, path string) bool {
+	if path == "" {
+		path = "/"
+	}
+
+	allowed := true
+	longestMatch := -1
+	for _, rule := range rules.rulesFor(userAgent) {
+		if !matchRobotsPattern(rule.pattern, path) {
+			continue
+		}
+
+		if len(rule.pattern) > longestMatch || (len(rule.pattern) == longestMatch && rule.allow) {
+			longestMatch = len(rule.pattern)
+			allowed = rule.allow
+		}
+	}
+
+	return allowed
+}
+
+// CrawlDelay returns the delay asked between two requests of userAgent, if
+// the robots.txt file sets one.
+func (rules *RobotsRules) CrawlDelay(userAgent string) (time.Duration, bool) {
+	for _, group := range rules.groupsFor(userAgent) {
+		if group.hasCrawlDelay {
+			return group.crawlDelay, true
+		}
+	}
+
+	return 0, false
+}
+
+// Sitemaps returns the urls of the Sitemap directives.
+func (rules *RobotsRules) Sitemaps() []string {
+	return rules.sitemaps
+}
+
+func (rules *RobotsRules) rulesFor(userAgent string) []robotsRule {
+	matchedRules := make([]robotsRule, 0)
+	for _, group := range rules.groupsFor(userAgent) {
+		matchedRules = append(matchedRules, group.rules...)
+	}
+
+	return matchedRules
+}
+
+// groupsFor returns the groups whose user-agent is the most specific match
+// for userAgent, falling back on the groups for "*".
+func (rules *RobotsRules) groupsFor(userAgent string) []robotsGroup {
+	userAgent = strings.ToLower(userAgent)
+
+	matchedGroups := make([]robotsGroup, 0)
+	wildcardGroups := make([]robotsGroup, 0)
+	longestMatch := 0
+	for _, group := range rules.groups {
+		groupMatch := 0
+		wildcard := false
+		for _, groupAgent := range group.userAgents {
+			if groupAgent == "*" {
+				wildcard = true
+			} else if groupAgent != "" && strings.Contains(userAgent, groupAgent) && len(groupAgent) > groupMatch {
+				groupMatch = len(groupAgent)
+			}
+		}
+
+		if groupMatch > longestMatch {
+			longestMatch = groupMatch
+			matchedGroups = matchedGroups[:0]
+		}
+
+		if groupMatch > 0 && groupMatch == longestMatch {
+			matchedGroups = append(matchedGroups, group)
+		} else if wildcard {
+			wildcardGroups = append(wildcardGroups, group)
+		}
+	}
+
+	if len(matchedGroups) > 0 {
+		return matchedGroups
+	}
+
+	return wildcardGroups
+}
+
+// matchRobotsPattern matches path against a robots.txt path pattern where
+// "*" stands for any sequence of characters and a trailing "$" anchors the
+// pattern at the end of the path.
+func matchRobotsPattern(pattern string, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = pattern[:len(pattern)-1]
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	position := len(parts[0])
+
+	for index := 1; index < len(parts); index++ {
+		part := parts[index]
+
+		if anchored && index == len(parts)-1 {
+			return strings.HasSuffix(path[position:], part)
+		}
+
+		found := strings.Index(path[position:], part)
+		if found < 0 {
+			return false
+		}
+		position += found + len(part)
+	}
+
+	if anchored {
+		return position == len(path)
+	}
+
+	return true
+}
@@ -0,0 +1,166 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/lauevrar77/dyzone/domain"
+)
+
+// DefaultRobotsRetryAfter is how long a robots.txt that could not be
+// fetched because of a server or network error disallows everything before
+// it is requested again.
+const DefaultRobotsRetryAfter = time.Hour
+
+// RobotsDisallowedError is returned when robots.txt forbids fetching a url.
+type RobotsDisallowedError struct {
+	URL       string
+	UserAgent string
+}
+
+func (err *RobotsDisallowedError) Error() string {
+	return fmt.Sprintf("robots.txt disallows %s for user agent %s", err.URL, err.UserAgent)
+}
+
+type robotsDownloader struct {
+	downloader Downloader
+	userAgent  string
+	retryAfter time.Duration
+
+	lock  sync.Mutex
+	hosts map[string]*robotsEntry
+}
+
+// disallowAllRobots stands for a robots.txt that could not be fetched
+// because of a server or network error.
+var disallowAllRobots = ParseRobots([]byte("User-agent: *\nDisallow: /\n"))
+
+type robotsEntry struct {
+	lock    sync.Mutex
+	rules   *RobotsRules
+	expires time.Time
+}
+
+// NewRobotsDownloader wraps downloader so that urls disallowed by the
+// robots.txt of their host for userAgent are refused with a
+// *RobotsDisallowedError. robots.txt files are fetched through downloader
+// once per scheme and host and kept for the lifetime of the returned
+// downloader.
+// As in RFC 9309, a robots.txt answered with a 4xx status allows everything
+// while a 5xx status or a network error disallows everything until it is
+// requested again, DefaultRobotsRetryAfter later.
+func NewRobotsDownloader(downloader Downloader, userAgent string) *robotsDownloader {
+	return &robotsDownloader{
+		downloader: downloader,
+		userAgent:  userAgent,
+		retryAfter: DefaultRobotsRetryAfter,
+		hosts:      make(map[string]*robotsEntry),
+	}
+}
+
+// ChangeRetryAfter changes how long an unreachable robots.txt disallows
+// everything before it is requested again.
+func (downloader *robotsDownloader) ChangeRetryAfter(retryAfter time.Duration) {
+	downloader.retryAfter = retryAfter
+}
+
+func (downloader *robotsDownloader) Download(url string) (*domain.WebResource, error) {
+	return downloader.DownloadContext(context.Background(), url)
+}
+
+func (downloader *robotsDownloader) DownloadContext(ctx context.Context, rawUrl string) (*domain.WebResource, error) {
+	parsedUrl, err := url.Parse(rawUrl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := downloader.rules(ctx, parsedUrl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !rules.Allowed(downloader.userAgent, parsedUrl.RequestURI()) {
+		return nil, &RobotsDisallowedError{URL: rawUrl, UserAgent: downloader.userAgent}
+	}
+
+	return DownloadContext(ctx, downloader.downloader, rawUrl)
+}
+
+// Rules returns the robots.txt rules applying to the host of rawUrl,
+// fetching them if needed.
+func (downloader *robotsDownloader) Rules(ctx context.Context, rawUrl string) (*RobotsRules, error) {
+	parsedUrl, err := url.Parse(rawUrl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return downloader.rules(ctx, parsedUrl)
+}
+
+func (downloader *robotsDownloader) rules(ctx context.Context, parsedUrl *url.URL) (*RobotsRules, error) {
+	robotsUrl := url.URL{Scheme: parsedUrl.Scheme, Host: parsedUrl.Host, Path: "/robots.txt"}
+
+	downloader.lock.Lock()
+	entry, found := downloader.hosts[robotsUrl.String()]
+	if !found {
+		entry = &robotsEntry{}
+		downloader.hosts[robotsUrl.String()] = entry
+	}
+	downloader.lock.Unlock()
+
+	// Holding the entry lock while fetching makes concurrent downloads of
+	// the same host wait for a single robots.txt request.
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	if entry.rules != nil && (entry.expires.IsZero() || time.Now().Before(entry.expires)) {
+		return entry.rules, nil
+	}
+
+	webResource, err := DownloadContext(ctx, downloader.downloader, robotsUrl.String())
+
+	if err != nil {
+		// Do not remember a robots.txt that could not be fetched only
+		// because the download was cancelled.
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		var statusError *StatusError
+		if errors.As(err, &statusError) && statusError.StatusCode >= 400 && statusError.StatusCode < 500 {
+			log.Printf("Could not fetch %s, allowing everything : %s\n", robotsUrl.String(), err)
+			entry.rules = &RobotsRules{}
+			entry.expires = time.Time{}
+			return entry.rules, nil
+		}
+
+		log.Printf("Could not fetch %s, disallowing everything : %s\n", robotsUrl.String(), err)
+		entry.rules = disallowAllRobots
+		entry.expires = time.Now().Add(downloader.retryAfter)
+		return entry.rules, nil
+	}
+
+	entry.rules = ParseRobots(webResource.RawContent())
+	entry.expires = time.Time{}
+	return entry.rules, nil
+}
+
+// CrawlDelay returns the Crawl-delay set by the robots.txt of the host of
+// rawUrl for the user agent of the downloader.
+func (downloader *robotsDownloader) CrawlDelay(ctx context.Context, rawUrl string) (time.Duration, bool) {
+	rules, err := downloader.Rules(ctx, rawUrl)
+
+	if err != nil {
+		return 0, false
+	}
+
+	return rules.CrawlDelay(downloader.userAgent)
+}
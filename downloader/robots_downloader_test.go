@@ -0,0 +1,181 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/lauevrar77/dyzone/domain"
+	"github.com/lauevrar77/dyzone/mocks"
+)
+
+func makeRobotsDownloaderFunc(robots string, fetched map[string]int, lock *sync.Mutex) func(url string) (*domain.WebResource, error) {
+	return func(url string) (*domain.WebResource, error) {
+		lock.Lock()
+		fetched[url]++
+		lock.Unlock()
+
+		if url == "https://example.com/robots.txt" {
+			return domain.NewWebResource(url, "text/plain", []byte(robots))
+		}
+
+		if url == "https://other.com/robots.txt" {
+			return nil, &StatusError{URL: url, StatusCode: 404, Status: "404 Not Found"}
+		}
+
+		if url == "https://down.com/robots.txt" {
+			return nil, &StatusError{URL: url, StatusCode: 503, Status: "503 Service Unavailable"}
+		}
+
+		if url == "https://unreachable.com/robots.txt" {
+			return nil, errors.New("connection refused")
+		}
+
+		return domain.NewWebResource(url, "text/html", []byte("Hello, World!"))
+	}
+}
+
+func TestRobotsDownloader(t *testing.T) {
+	var lock sync.Mutex
+	fetched := make(map[string]int)
+	mock := mocks.NewDownloaderMock(makeRobotsDownloaderFunc(robotsContent, fetched, &lock))
+	downloader := NewRobotsDownloader(mock, "somebot")
+
+	webResource, err := downloader.Download("https://example.com/index.html")
+
+	if err != nil || webResource == nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	_, err = downloader.Download("https://example.com/private/secret.html")
+
+	var disallowed *RobotsDisallowedError
+	if !errors.As(err, &disallowed) {
+		t.Logf("Wrong error : %v", err)
+		t.FailNow()
+	}
+
+	if disallowed.URL != "https://example.com/private/secret.html" || disallowed.UserAgent != "somebot" {
+		t.Logf("Wrong disallowed error : %v", disallowed)
+		t.Fail()
+	}
+
+	if fetched["https://example.com/robots.txt"] != 1 {
+		t.Log("robots.txt should be fetched once")
+		t.Fail()
+	}
+
+	if fetched["https://example.com/private/secret.html"] != 0 {
+		t.Log("Disallowed url should not be fetched")
+		t.Fail()
+	}
+}
+
+func TestRobotsDownloaderMissingRobots(t *testing.T) {
+	var lock sync.Mutex
+	fetched := make(map[string]int)
+	mock := mocks.NewDownloaderMock(makeRobotsDownloaderFunc(robotsContent, fetched, &lock))
+	downloader := NewRobotsDownloader(mock, "somebot")
+
+	_, err := downloader.Download("https://other.com/private/")
+
+	if err != nil {
+		t.Log(err)
+		t.Fail()
+	}
+}
+
+func TestRobotsDownloaderUnavailableRobots(t *testing.T) {
+	var lock sync.Mutex
+	fetched := make(map[string]int)
+	mock := mocks.NewDownloaderMock(makeRobotsDownloaderFunc(robotsContent, fetched, &lock))
+	downloader := NewRobotsDownloader(mock, "somebot")
+
+	for _, url := range []string{"https://down.com/index.html", "https://unreachable.com/index.html"} {
+		_, err := downloader.Download(url)
+
+		var disallowed *RobotsDisallowedError
+		if !errors.As(err, &disallowed) {
+			t.Logf("Wrong error for %s : %v", url, err)
+			t.Fail()
+		}
+
+		if fetched[url] != 0 {
+			t.Logf("%s should not be fetched", url)
+			t.Fail()
+		}
+	}
+
+	downloader.Download("https://down.com/other.html")
+	if fetched["https://down.com/robots.txt"] != 1 {
+		t.Log("robots.txt should not be fetched again before the retry delay")
+		t.Fail()
+	}
+
+	downloader = NewRobotsDownloader(mock, "somebot")
+	downloader.ChangeRetryAfter(0)
+	downloader.Download("https://down.com/index.html")
+	downloader.Download("https://down.com/index.html")
+	if fetched["https://down.com/robots.txt"] != 3 {
+		t.Logf("robots.txt fetched %d times", fetched["https://down.com/robots.txt"])
+		t.Fail()
+	}
+}
+
+func TestRobotsDownloaderSchemes(t *testing.T) {
+	var lock sync.Mutex
+	fetched := make(map[string]int)
+	mock := mocks.NewDownloaderMock(makeRobotsDownloaderFunc(robotsContent, fetched, &lock))
+	downloader := NewRobotsDownloader(mock, "somebot")
+
+	downloader.Download("https://example.com/index.html")
+	downloader.Download("http://example.com/index.html")
+
+	if fetched["https://example.com/robots.txt"] != 1 || fetched["http://example.com/robots.txt"] != 1 {
+		t.Logf("robots.txt should be fetched once per scheme : %v", fetched)
+		t.Fail()
+	}
+}
+
+func TestRobotsDownloaderConcurrentFetch(t *testing.T) {
+	var lock sync.Mutex
+	fetched := make(map[string]int)
+	mock := mocks.NewDownloaderMock(makeRobotsDownloaderFunc(robotsContent, fetched, &lock))
+	downloader := NewRobotsDownloader(mock, "somebot")
+
+	var group sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			downloader.Download("https://example.com/index.html")
+		}()
+	}
+	group.Wait()
+
+	if fetched["https://example.com/robots.txt"] != 1 {
+		t.Logf("robots.txt fetched %d times", fetched["https://example.com/robots.txt"])
+		t.Fail()
+	}
+}
+
+func TestRobotsDownloaderRules(t *testing.T) {
+	var lock sync.Mutex
+	fetched := make(map[string]int)
+	mock := mocks.NewDownloaderMock(makeRobotsDownloaderFunc(robotsContent, fetched, &lock))
+	downloader := NewRobotsDownloader(mock, "somebot")
+
+	rules, err := downloader.Rules(context.Background(), "https://example.com/")
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if len(rules.Sitemaps()) != 1 {
+		t.Log("Sitemaps should be read from robots.txt")
+		t.Fail()
+	}
+}
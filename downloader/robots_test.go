@@ -0,0 +1,133 @@
+package downloader
+
+import (
+	"testing"
+	"time"
+)
+
+const robotsContent = `
+# Example robots.txt
+User-agent: *
+Disallow: /private/
+Allow: /private/public.html
+Disallow: /*.pdf$
+Disallow: /search?*q=
+Crawl-delay: 2
+
+User-agent: dyzone
+User-agent: otherbot
+Disallow: /not-for-dyzone
+Crawl-delay: 0.5
+
+User-agent: dyzone-images
+Disallow: /
+
+Sitemap: https://example.com/sitemap.xml
+`
+
+func TestRobotsAllowed(t *testing.T) {
+	rules := ParseRobots([]byte(robotsContent))
+
+	cases := []struct {
+		userAgent string
+		path      string
+		allowed   bool
+	}{
+		{"somebot", "/", true},
+		{"somebot", "/private/", false},
+		{"somebot", "/private/secret.html", false},
+		{"somebot", "/private/public.html", true},
+		{"somebot", "/docs/file.pdf", false},
+		{"somebot", "/docs/file.pdf?download=1", true},
+		{"somebot", "/search?lang=en&q=go", false},
+		{"somebot", "/search", true},
+		{"Dyzone/1.0", "/private/", true},
+		{"Dyzone/1.0", "/not-for-dyzone/page.html", false},
+		{"OtherBot", "/not-for-dyzone", false},
+		{"dyzone-images/1.0", "/index.html", false},
+	}
+
+	for _, c := range cases {
+		if rules.Allowed(c.userAgent, c.path) != c.allowed {
+			t.Logf("%s fetching %s should be allowed : %t", c.userAgent, c.path, c.allowed)
+			t.Fail()
+		}
+	}
+}
+
+func TestRobotsCrawlDelay(t *testing.T) {
+	rules := ParseRobots([]byte(robotsContent))
+
+	delay, found := rules.CrawlDelay("somebot")
+	if !found || delay != 2*time.Second {
+		t.Logf("Wrong crawl delay %s", delay)
+		t.Fail()
+	}
+
+	delay, found = rules.CrawlDelay("dyzone")
+	if !found || delay != 500*time.Millisecond {
+		t.Logf("Wrong crawl delay %s", delay)
+		t.Fail()
+	}
+
+	_, found = rules.CrawlDelay("dyzone-images")
+	if found {
+		t.Log("No crawl delay should be found")
+		t.Fail()
+	}
+}
+
+func TestRobotsSitemaps(t *testing.T) {
+	rules := ParseRobots([]byte(robotsContent))
+
+	sitemaps := rules.Sitemaps()
+	if len(sitemaps) != 1 || sitemaps[0] != "https://example.com/sitemap.xml" {
+		t.Logf("Wrong sitemaps %s", sitemaps)
+		t.Fail()
+	}
+}
+
+func TestEmptyRobotsAllowsEverything(t *testing.T) {
+	rules := ParseRobots([]byte(""))
+
+	if !rules.Allowed("dyzone", "/private/") {
+		t.Log("Empty robots.txt should allow everything")
+		t.Fail()
+	}
+
+	rules = ParseRobots([]byte("User-agent: *\nDisallow:\n"))
+
+	if !rules.Allowed("dyzone", "/private/") {
+		t.Log("Empty Disallow should allow everything")
+		t.Fail()
+	}
+}
+
+func TestMatchRobotsPattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/", "/anything", true},
+		{"/fish", "/fish.html", true},
+		{"/fish", "/Fish.html", false},
+		{"/fish*", "/fishheads/yummy.html", true},
+		{"/fish/", "/fish", false},
+		{"/*.php", "/folder/filename.php?parameters", true},
+		{"/*.php$", "/filename.php", true},
+		{"/*.php$", "/filename.php?parameters", false},
+		{"/fish*.php", "/fishheads/catfish.php?parameters", true},
+		{"/fish*.php", "/Fish.PHP", false},
+		{"/exact$", "/exact", true},
+		{"/exact$", "/exact/", false},
+		{"/*$", "/anything", true},
+	}
+
+	for _, c := range cases {
+		if matchRobotsPattern(c.pattern, c.path) != c.match {
+			t.Logf("%s matching %s should be %t", c.pattern, c.path, c.match)
+			t.Fail()
+		}
+	}
+}
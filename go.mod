@@ -5,4 +5,6 @@ go 1.15
 require (
 	github.com/antchfx/htmlquery v1.2.3
 	github.com/prometheus/common v0.15.0
+	golang.org/x/net v0.0.0-20200625001655-4c5254603344
+	golang.org/x/text v0.3.2
 )
@@ -12,6 +12,10 @@ func NewHttpMockClient(getFunc func() (*http.Response, error)) MockHttpClient {
 	}
 }
 
+func (client MockHttpClient) Do(request *http.Request) (*http.Response, error) {
+	return client.getFunc()
+}
+
 func (client MockHttpClient) Get(url string) (*http.Response, error) {
 	return client.getFunc()
 }
@@ -0,0 +1,205 @@
+package pipeline
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lauevrar77/dyzone/domain"
+)
+
+var unsafeFileCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// maxFileNameLength keeps the file and directory names written by the
+// pipeline well below the 255 bytes most file systems accept.
+const maxFileNameLength = 200
+
+// MarkdownPipelineOption configures a pipeline built with NewMarkdownPipeline.
+type MarkdownPipelineOption func(pipeline *markdownPipeline)
+
+// WithMainContent only converts the main content of the pages, as found by
+// domain.WebResource.MainText, instead of their whole body.
+func WithMainContent() MarkdownPipelineOption {
+	return func(pipeline *markdownPipeline) {
+		pipeline.mainContent = true
+	}
+}
+
+// WithFrontMatter starts every file with a YAML front matter holding the
+// title, url and fetch time of the page.
+func WithFrontMatter() MarkdownPipelineOption {
+	return func(pipeline *markdownPipeline) {
+		pipeline.frontMatter = true
+	}
+}
+
+type markdownPipeline struct {
+	directory   string
+	mainContent bool
+	frontMatter bool
+}
+
+// NewMarkdownPipeline builds a pipeline converting every web page to
+// GitHub Flavored Markdown and writing it to a .md file under directory.
+// Other resources go through untouched. See Path for the file names.
+func NewMarkdownPipeline(directory string, options ...MarkdownPipelineOption) *markdownPipeline {
+	pipeline := &markdownPipeline{directory: directory}
+
+	for _, option := range options {
+		option(pipeline)
+	}
+
+	return pipeline
+}
+
+func (pipeline *markdownPipeline) ManageWebResource(webResource *domain.WebResource) (*domain.WebResource, error) {
+	if !webResource.IsWebPage() {
+		return webResource, nil
+	}
+
+	title, markdown, err := pipeline.convert(webResource)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if pipeline.frontMatter {
+		markdown = frontMatter(title, webResource) + markdown
+	}
+
+	filePath := pipeline.Path(webResource)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return nil, err
+	}
+
+	if err := ioutil.WriteFile(filePath, []byte(markdown), 0644); err != nil {
+		return nil, err
+	}
+
+	return webResource, nil
+}
+
+// Path returns the file a web page is written to : the host and path of its
+// url below the directory of the pipeline, with a .md extension.
+// Directory urls are written to index.md. Names built from a query or from
+// a path whose .html, .htm or .php extension was dropped end with a short
+// hash of the path and query, so that they do not collide with other urls,
+// e.g. https://example.com/blog/?page=2 goes to
+// example.com/blog/index_page_2_<hash>.md.
+// Names longer than maxFileNameLength are truncated.
+func (pipeline *markdownPipeline) Path(webResource *domain.WebResource) string {
+	pageUrl, err := url.Parse(webResource.FinalURL())
+	if err != nil {
+		return filepath.Join(pipeline.directory, "index.md")
+	}
+
+	segments := []string{sanitizeFileName(pageUrl.Host)}
+	for _, segment := range strings.Split(pageUrl.Path, "/") {
+		if segment != "" {
+			segments = append(segments, sanitizeFileName(segment))
+		}
+	}
+
+	name := "index"
+	rewritten := false
+	if !strings.HasSuffix(pageUrl.Path, "/") && len(segments) > 1 {
+		name = segments[len(segments)-1]
+		segments = segments[:len(segments)-1]
+
+		if extension := path.Ext(name); extension == ".html" || extension == ".htm" || extension == ".php" {
+			name = strings.TrimSuffix(name, extension)
+			rewritten = true
+		}
+	}
+
+	if pageUrl.RawQuery != "" {
+		name += "_" + sanitizeFileName(pageUrl.RawQuery)
+		rewritten = true
+	}
+
+	if rewritten {
+		hash := shortHash(pageUrl.EscapedPath() + "?" + pageUrl.RawQuery)
+		name = truncateFileName(name, maxFileNameLength-len(hash)-1) + "_" + hash
+	}
+
+	segments = append(segments, name+".md")
+
+	return filepath.Join(append([]string{pipeline.directory}, segments...)...)
+}
+
+func (pipeline *markdownPipeline) convert(webResource *domain.WebResource) (string, string, error) {
+	if pipeline.mainContent {
+		content, err := webResource.MainText()
+		if err != nil {
+			return "", "", err
+		}
+
+		markdown, err := domain.HTMLToMarkdown(content.HTML)
+		return content.Title, markdown, err
+	}
+
+	metadata, err := webResource.Metadata()
+	if err != nil {
+		return "", "", err
+	}
+
+	markdown, err := webResource.Markdown()
+	return metadata.Title, markdown, err
+}
+
+func frontMatter(title string, webResource *domain.WebResource) string {
+	lines := []string{
+		"---",
+		"title: " + strconv.Quote(title),
+		"url: " + strconv.Quote(webResource.FinalURL()),
+	}
+
+	if fetchedAt := webResource.FetchedAt(); !fetchedAt.IsZero() {
+		lines = append(lines, "fetched_at: "+fetchedAt.UTC().Format(time.RFC3339))
+	}
+
+	return strings.Join(append(lines, "---", ""), "\n") + "\n"
+}
+
+// sanitizeFileName replaces the characters that are not safe in file names,
+// and the . and .. names, with underscores.
+func sanitizeFileName(name string) string {
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+
+	sanitized := unsafeFileCharacters.ReplaceAllString(name, "_")
+	if strings.Trim(sanitized, ".") == "" {
+		return "_"
+	}
+
+	if len(sanitized) > maxFileNameLength {
+		hash := shortHash(name)
+		sanitized = truncateFileName(sanitized, maxFileNameLength-len(hash)-1) + "_" + hash
+	}
+
+	return sanitized
+}
+
+// truncateFileName cuts a sanitized name, only made of ASCII characters, to
+// length bytes.
+func truncateFileName(name string, length int) string {
+	if len(name) > length {
+		return name[:length]
+	}
+
+	return name
+}
+
+func shortHash(value string) string {
+	sum := sha1.Sum([]byte(value))
+	return hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,174 @@
+package pipeline
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lauevrar77/dyzone/domain"
+)
+
+func TestMarkdownPipelinePath(t *testing.T) {
+	pipeline := NewMarkdownPipeline("out")
+
+	cases := []struct {
+		url      string
+		expected string
+	}{
+		{"https://example.com", "out/example.com/index.md"},
+		{"https://example.com/", "out/example.com/index.md"},
+		{"https://example.com/blog/", "out/example.com/blog/index.md"},
+		{"https://example.com/blog/post.html", "out/example.com/blog/post_be6b25ff.md"},
+		{"https://example.com/blog/post", "out/example.com/blog/post.md"},
+		{"https://example.com/blog/?page=2", "out/example.com/blog/index_page_2_86748cf1.md"},
+		{"https://example.com/?a=b", "out/example.com/index_a_b_343fa5f7.md"},
+		{"https://example.com/?a_b", "out/example.com/index_a_b_67bd6ab2.md"},
+		{"https://example.com:8080/a%20b/../c", "out/example.com_8080/a_b/_/c.md"},
+	}
+
+	for _, c := range cases {
+		webResource, err := domain.NewWebResource(c.url, "text/html", []byte{})
+		if err != nil {
+			t.Logf("Could not create WebResource for %s", c.url)
+			t.FailNow()
+		}
+
+		if path := pipeline.Path(webResource); path != filepath.FromSlash(c.expected) {
+			t.Logf("%s : path %s different of %s", c.url, path, c.expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestMarkdownPipelinePathCollisions(t *testing.T) {
+	pipeline := NewMarkdownPipeline("out")
+
+	urls := []string{
+		"https://example.com/a",
+		"https://example.com/a.html",
+		"https://example.com/a.php",
+		"https://example.com/?a=b",
+		"https://example.com/?a_b",
+		"https://example.com/?a=b&c=d",
+		"https://example.com/?a=b%26c=d",
+	}
+
+	paths := make(map[string]string)
+	for _, rawUrl := range urls {
+		webResource, _ := domain.NewWebResource(rawUrl, "text/html", []byte{})
+		path := pipeline.Path(webResource)
+
+		if other, found := paths[path]; found {
+			t.Logf("%s and %s both written to %s", other, rawUrl, path)
+			t.Fail()
+		}
+		paths[path] = rawUrl
+	}
+}
+
+func TestMarkdownPipelineLongNames(t *testing.T) {
+	directory, err := ioutil.TempDir("", "markdown_pipeline")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(directory)
+
+	pipeline := NewMarkdownPipeline(directory)
+
+	long := strings.Repeat("a", 300)
+	for _, rawUrl := range []string{"https://example.com/?q=" + long, "https://example.com/" + long + "/page", "https://example.com/" + long} {
+		webResource, _ := domain.NewWebResource(rawUrl, "text/html", []byte("<html><body><p>Hello</p></body></html>"))
+
+		for _, name := range strings.Split(pipeline.Path(webResource), string(filepath.Separator)) {
+			if len(name) > 255 {
+				t.Logf("Name too long for %s : %d bytes", rawUrl, len(name))
+				t.Fail()
+			}
+		}
+
+		if _, err := pipeline.ManageWebResource(webResource); err != nil {
+			t.Log(err)
+			t.Fail()
+		}
+	}
+}
+
+func TestMarkdownPipeline(t *testing.T) {
+	directory, err := ioutil.TempDir("", "markdown_pipeline")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(directory)
+
+	pipeline := NewMarkdownPipeline(directory, WithFrontMatter())
+
+	webResource, _ := domain.NewWebResource("https://example.com/docs/", "text/html", []byte(`<title>Docs</title><h1>Docs</h1><p>See <a href="install">install</a>.</p>`))
+	webResource.ChangeResponseMetadata(domain.ResponseMetadata{FetchedAt: time.Date(2020, 5, 1, 8, 0, 0, 0, time.UTC)})
+
+	result, err := pipeline.ManageWebResource(webResource)
+	if err != nil || result != webResource {
+		t.Logf("Page should go through the pipeline : %v", err)
+		t.FailNow()
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(directory, "example.com", "docs", "index.md"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	expected := "---\n" +
+		"title: \"Docs\"\n" +
+		"url: \"https://example.com/docs/\"\n" +
+		"fetched_at: 2020-05-01T08:00:00Z\n" +
+		"---\n\n" +
+		"# Docs\n\n" +
+		"See [install](https://example.com/docs/install).\n"
+	if string(content) != expected {
+		t.Logf("Content\n%s\ndifferent of\n%s", content, expected)
+		t.Fail()
+	}
+
+	image, _ := domain.NewWebResource("https://example.com/logo.png", "image/png", []byte("\x89PNG\x0D\x0A\x1A\x0A"))
+	if result, err := pipeline.ManageWebResource(image); err != nil || result != image {
+		t.Log("Other resources should go through untouched")
+		t.Fail()
+	}
+
+	if _, err := os.Stat(filepath.Join(directory, "example.com", "logo.md")); !os.IsNotExist(err) {
+		t.Log("No file should be written for other resources")
+		t.Fail()
+	}
+}
+
+func TestMarkdownPipelineMainContent(t *testing.T) {
+	directory, err := ioutil.TempDir("", "markdown_pipeline")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(directory)
+
+	pipeline := NewMarkdownPipeline(directory, WithMainContent())
+
+	webResource, _ := domain.NewWebResource("https://example.com/post", "text/html", []byte(`
+		<nav><a href="/">Home</a></nav>
+		<article><p>The main content of the page is long enough, to be kept, by the extraction.</p></article>
+		<footer>Copyright</footer>`))
+
+	if _, err := pipeline.ManageWebResource(webResource); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	content, _ := ioutil.ReadFile(filepath.Join(directory, "example.com", "post.md"))
+	if !strings.Contains(string(content), "The main content") || strings.Contains(string(content), "Home") || strings.Contains(string(content), "Copyright") {
+		t.Logf("Only the main content should be written, got %s", content)
+		t.Fail()
+	}
+}
@@ -1,6 +1,9 @@
 package dyzone
 
 import (
+	"context"
+	"errors"
+
 	"github.com/lauevrar77/dyzone/domain"
 	"github.com/lauevrar77/dyzone/downloader"
 )
@@ -13,62 +16,217 @@ type WebResourcePipeline interface {
 	ManageWebResource(webResource *domain.WebResource) (*domain.WebResource, error)
 }
 
+// Result is a single item of a SpiderRunner.Stream.
+// Exactly one of WebResource and Err is set.
+type Result struct {
+	WebResource *domain.WebResource
+	Err         error
+}
+
 type SpiderRunner struct {
 	downloader downloader.Downloader
 	spider     Spider
 	pipeline   WebResourcePipeline
+	workers    int
+	visitedSet VisitedSet
+	normalizer domain.URLNormalizer
+	maxDepth   int
+	maxPages   int
+	maxBytes   int64
+	robotsMeta bool
+
+	errorPolicy ErrorPolicy
+	maxErrors   int
 }
 
-func (runner SpiderRunner) Run(startUrl string) ([]*domain.WebResource, error) {
-	resources := make([]*domain.WebResource, 0)
+// RunnerOption configures a SpiderRunner at construction time.
+type RunnerOption func(runner *SpiderRunner)
 
-	// Run Downloader
-	webResource, err := runner.downloader.Download(startUrl)
+// WithWorkers sets how many urls are downloaded, scraped and pipelined
+// concurrently. Downloader, Spider and WebResourcePipeline implementations
+// must be safe for concurrent use when more than one worker is configured.
+func WithWorkers(workers int) RunnerOption {
+	return func(runner *SpiderRunner) {
+		if workers < 1 {
+			workers = 1
+		}
+		runner.workers = workers
+	}
+}
 
-	if err != nil {
-		return nil, err
+// WithVisitedSet shares the given VisitedSet between every run of the
+// runner instead of starting each run with an empty in-memory set.
+func WithVisitedSet(visitedSet VisitedSet) RunnerOption {
+	return func(runner *SpiderRunner) {
+		runner.visitedSet = visitedSet
 	}
+}
 
-	// Give result to spider to generate following requests and result
-	newRequests, webResource, err := runner.spider.OnWebResourceFetched(webResource)
+// WithURLNormalizer replaces the rules used to normalize urls before they
+// are looked up in the VisitedSet. It defaults to
+// domain.DefaultNormalizationRules.
+func WithURLNormalizer(normalizer domain.URLNormalizer) RunnerOption {
+	return func(runner *SpiderRunner) {
+		runner.normalizer = normalizer
+	}
+}
 
-	if err != nil {
-		return nil, err
+// WithMaxDepth stops following links found more than depth links away from
+// the start url. The start url has a depth of 0, so a depth of 0 only
+// downloads the start url. A negative depth, the default, means no limit.
+func WithMaxDepth(depth int) RunnerOption {
+	return func(runner *SpiderRunner) {
+		runner.maxDepth = depth
 	}
+}
 
-	// Send found resource into the management pipeline
-	if webResource != nil {
-		webResource, err = runner.pipeline.ManageWebResource(webResource)
+// WithMaxPages stops the crawl once pages urls have been downloaded.
+// A value lower than 1 means no limit.
+func WithMaxPages(pages int) RunnerOption {
+	return func(runner *SpiderRunner) {
+		runner.maxPages = pages
+	}
+}
 
-		if err != nil {
-			return nil, err
-		}
+// WithMaxBytes stops the crawl once the downloaded contents add up to bytes.
+// Downloads already running when the budget is reached still complete.
+// A value lower than 1 means no limit.
+func WithMaxBytes(bytes int64) RunnerOption {
+	return func(runner *SpiderRunner) {
+		runner.maxBytes = bytes
+	}
+}
 
-		if webResource != nil {
-			resources = append(resources, webResource)
+// WithErrorPolicy chooses whether a failing url stops the whole crawl or is
+// recorded and skipped.
+func WithErrorPolicy(policy ErrorPolicy) RunnerOption {
+	return func(runner *SpiderRunner) {
+		runner.errorPolicy = policy
+	}
+}
+
+// WithMaxErrors records and skips failing urls until maxErrors failures
+// have been recorded, then stops the crawl.
+func WithMaxErrors(maxErrors int) RunnerOption {
+	return func(runner *SpiderRunner) {
+		runner.errorPolicy = SkipAndRecord
+		runner.maxErrors = maxErrors
+	}
+}
+
+// WithPoliteness spaces and caps the requests sent to each host by wrapping
+// the downloader of the runner with downloader.NewPoliteDownloader.
+func WithPoliteness(options ...downloader.PolitenessOption) RunnerOption {
+	return func(runner *SpiderRunner) {
+		runner.downloader = downloader.NewPoliteDownloader(runner.downloader, options...)
+	}
+}
+
+// WithRobotsMeta makes the runner respect the noindex and nofollow
+// directives of the robots <meta> tags and X-Robots-Tag headers of the
+// downloaded pages : urls found on nofollow pages are not followed and
+// noindex pages are not sent to the pipeline.
+func WithRobotsMeta() RunnerOption {
+	return func(runner *SpiderRunner) {
+		runner.robotsMeta = true
+	}
+}
+
+// Run crawls from startUrl and returns the resources that went through the
+// pipeline. Reaching a depth, page or byte limit is not an error : the
+// resources collected so far are returned.
+//
+// With the FailFast policy, the first failure is returned as a *CrawlError.
+// With the SkipAndRecord policy, the failures are returned as *CrawlErrors
+// once the crawl is over or the WithMaxErrors threshold is reached, in which
+// case its Cause is ErrTooManyErrors.
+// In every case, the resources collected so far are returned as well.
+func (runner SpiderRunner) Run(startUrl string) ([]*domain.WebResource, error) {
+	return runner.RunContext(context.Background(), startUrl)
+}
+
+// RunContext is like Run but stops the crawl when ctx is cancelled or reaches
+// its deadline. ctx is passed down to downloaders implementing
+// downloader.ContextDownloader. The resources collected before the crawl
+// stopped are returned along with ctx.Err(), or *CrawlErrors caused by
+// ctx.Err() when failures were recorded.
+func (runner SpiderRunner) RunContext(ctx context.Context, startUrl string) ([]*domain.WebResource, error) {
+	resources := make([]*domain.WebResource, 0)
+	crawlErrors := make([]*CrawlError, 0)
+
+	err := newCrawl(ctx, runner).run(startUrl, func(result Result) {
+		var crawlError *CrawlError
+		if errors.As(result.Err, &crawlError) {
+			crawlErrors = append(crawlErrors, crawlError)
+			return
 		}
+
+		resources = append(resources, result.WebResource)
+	})
+
+	if len(crawlErrors) > 0 {
+		return resources, &CrawlErrors{Errors: crawlErrors, Cause: err}
 	}
 
-	// Perform following requests recusively
-	for _, request := range newRequests {
-		childrenResources, err := runner.Run(request)
+	return resources, err
+}
 
-		if err != nil {
-			return nil, err
+// Stream crawls from startUrl in the background and sends every resource
+// coming out of the pipeline on the returned channel as soon as it is ready.
+// With the SkipAndRecord policy, each failure is sent as a *CrawlError.
+// The crawl only moves forward as results are received, so at most one
+// result is kept in memory on behalf of a slow consumer.
+// When the crawl fails or ctx is done, a last Result holding the error is
+// sent. The channel is closed at the end of the crawl and must be drained
+// until then, unless ctx is done.
+func (runner SpiderRunner) Stream(ctx context.Context, startUrl string) <-chan Result {
+	// The buffer keeps room for the last error when the consumer stopped
+	// reading after cancelling ctx.
+	results := make(chan Result, 1)
+
+	go func() {
+		defer close(results)
+
+		err := newCrawl(ctx, runner).run(startUrl, func(result Result) {
+			select {
+			case results <- result:
+			case <-ctx.Done():
+			}
+		})
+
+		if err == nil {
+			return
 		}
 
-		for _, childResource := range childrenResources {
-			resources = append(resources, childResource)
+		select {
+		case results <- Result{Err: err}:
+		case <-ctx.Done():
+			// The error takes the place of a result left unread, this
+			// goroutine being the only sender the send cannot block.
+			select {
+			case <-results:
+			default:
+			}
+			results <- Result{Err: err}
 		}
-	}
+	}()
 
-	return resources, nil
+	return results
 }
 
-func NewSpiderRunner(downloader downloader.Downloader, spider Spider, pipeline WebResourcePipeline) SpiderRunner {
-	return SpiderRunner{
+func NewSpiderRunner(downloader downloader.Downloader, spider Spider, pipeline WebResourcePipeline, options ...RunnerOption) SpiderRunner {
+	runner := SpiderRunner{
 		downloader: downloader,
 		spider:     spider,
 		pipeline:   pipeline,
+		workers:    1,
+		maxDepth:   -1,
+		normalizer: domain.NewURLNormalizer(domain.DefaultNormalizationRules...),
 	}
+
+	for _, option := range options {
+		option(&runner)
+	}
+
+	return runner
 }
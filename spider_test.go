@@ -1,10 +1,16 @@
 package dyzone
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/lauevrar77/dyzone/domain"
+	dyzoneDownloader "github.com/lauevrar77/dyzone/downloader"
 	"github.com/lauevrar77/dyzone/mocks"
 )
 
@@ -229,6 +235,721 @@ func TestSpiderRunnerFailingPipeline(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestSpiderRunnerConcurrentWorkers(t *testing.T) {
+	var lock sync.Mutex
+	running := 0
+	maxRunning := 0
+
+	downloader := mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		lock.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+
+		return workingDownloader(url)
+	})
+	spider := mocks.NewSpiderMock(treeSpiderFunc(3, 3))
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithWorkers(4))
+	resources, err := runner.Run("http://example.com/n")
+
+	if err != nil {
+		t.Log("Error in spider")
+		t.FailNow()
+	}
+
+	if len(resources) != 40 {
+		t.Logf("Wrong number of result resources : %d", len(resources))
+		t.Fail()
+	}
+
+	if maxRunning < 2 || maxRunning > 4 {
+		t.Logf("Wrong number of concurrent downloads : %d", maxRunning)
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerConcurrentFailure(t *testing.T) {
+	downloader := mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		if url == "http://example.com/n/1/1" {
+			return nil, errors.New("error")
+		}
+		return workingDownloader(url)
+	})
+	spider := mocks.NewSpiderMock(treeSpiderFunc(3, 3))
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithWorkers(4))
+	_, err := runner.Run("http://example.com/n")
+
+	if err == nil {
+		t.Log("Downloader should fail")
+		t.FailNow()
+	}
+}
+
+func TestSpiderRunnerVisitsOnce(t *testing.T) {
+	var lock sync.Mutex
+	downloads := make(map[string]int)
+
+	downloader := mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		lock.Lock()
+		downloads[url]++
+		lock.Unlock()
+
+		return workingDownloader(url)
+	})
+	spider := mocks.NewSpiderMock(cyclingSpiderFunc)
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithWorkers(2))
+	resources, err := runner.Run("http://example.com")
+
+	if err != nil {
+		t.Log("Error in spider")
+		t.FailNow()
+	}
+
+	if len(resources) != 3 {
+		t.Logf("Wrong number of result resources : %d", len(resources))
+		t.Fail()
+	}
+
+	for url, count := range downloads {
+		if count != 1 {
+			t.Logf("%s downloaded %d times", url, count)
+			t.Fail()
+		}
+	}
+}
+
+func TestSpiderRunnerSharedVisitedSet(t *testing.T) {
+	downloader := mocks.NewDownloaderMock(workingDownloader)
+	spider := mocks.NewSpiderMock(cyclingSpiderFunc)
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	visitedSet := NewMemoryVisitedSet()
+	visitedSet.Visit("http://example.com/about.html")
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithVisitedSet(visitedSet))
+	resources, err := runner.Run("http://example.com")
+
+	if err != nil {
+		t.Log("Error in spider")
+		t.FailNow()
+	}
+
+	if len(resources) != 2 {
+		t.Logf("Wrong number of result resources : %d", len(resources))
+		t.Fail()
+	}
+
+	resources, err = runner.Run("http://example.com")
+
+	if err != nil {
+		t.Log("Error in spider")
+		t.FailNow()
+	}
+
+	if len(resources) != 0 {
+		t.Logf("Visited urls should not be downloaded again : %d", len(resources))
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerMaxDepth(t *testing.T) {
+	downloader := mocks.NewDownloaderMock(workingDownloader)
+	spider := mocks.NewSpiderMock(treeSpiderFunc(2, 5))
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithMaxDepth(2))
+	resources, err := runner.Run("http://example.com/n")
+
+	if err != nil {
+		t.Log("Reaching the max depth should not be an error")
+		t.FailNow()
+	}
+
+	if len(resources) != 7 {
+		t.Logf("Wrong number of result resources : %d", len(resources))
+		t.Fail()
+	}
+
+	runner = NewSpiderRunner(downloader, spider, pipeline, WithMaxDepth(0))
+	resources, _ = runner.Run("http://example.com/n")
+
+	if len(resources) != 1 {
+		t.Logf("A max depth of 0 should only download the start url : %d", len(resources))
+		t.Fail()
+	}
+
+	runner = NewSpiderRunner(downloader, spider, pipeline, WithMaxDepth(-1))
+	resources, _ = runner.Run("http://example.com/n")
+
+	if len(resources) != 63 {
+		t.Logf("A negative max depth should not limit the crawl : %d", len(resources))
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerMaxPages(t *testing.T) {
+	downloader := mocks.NewDownloaderMock(workingDownloader)
+	spider := mocks.NewSpiderMock(treeSpiderFunc(2, 5))
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithMaxPages(5), WithWorkers(3))
+	resources, err := runner.Run("http://example.com/n")
+
+	if err != nil {
+		t.Log("Reaching the page budget should not be an error")
+		t.FailNow()
+	}
+
+	if len(resources) != 5 {
+		t.Logf("Wrong number of result resources : %d", len(resources))
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerMaxBytes(t *testing.T) {
+	downloader := mocks.NewDownloaderMock(workingDownloader)
+	spider := mocks.NewSpiderMock(treeSpiderFunc(2, 5))
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	// Every page weights 13 bytes, the budget is reached on the third one.
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithMaxBytes(30))
+	resources, err := runner.Run("http://example.com/n")
+
+	if err != nil {
+		t.Log("Reaching the byte budget should not be an error")
+		t.FailNow()
+	}
+
+	if len(resources) != 3 {
+		t.Logf("Wrong number of result resources : %d", len(resources))
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var lock sync.Mutex
+	downloads := 0
+	downloader := mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		lock.Lock()
+		downloads++
+		if downloads == 3 {
+			cancel()
+		}
+		lock.Unlock()
+
+		return workingDownloader(url)
+	})
+	spider := mocks.NewSpiderMock(treeSpiderFunc(2, 5))
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline)
+	resources, err := runner.RunContext(ctx, "http://example.com/n")
+
+	if !errors.Is(err, context.Canceled) {
+		t.Logf("Wrong error : %v", err)
+		t.Fail()
+	}
+
+	if len(resources) != 3 {
+		t.Logf("Partial results should be returned : %d", len(resources))
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	downloader := mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		time.Sleep(5 * time.Millisecond)
+		return workingDownloader(url)
+	})
+	spider := mocks.NewSpiderMock(treeSpiderFunc(2, 10))
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithWorkers(2))
+	resources, err := runner.RunContext(ctx, "http://example.com/n")
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Logf("Wrong error : %v", err)
+		t.Fail()
+	}
+
+	if len(resources) == 0 {
+		t.Log("Partial results should be returned")
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerStream(t *testing.T) {
+	firstReceived := make(chan struct{})
+	downloader := mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		if url != "http://example.com/n" {
+			// Following pages are only downloaded once the start page
+			// has been received by the consumer.
+			select {
+			case <-firstReceived:
+			case <-time.After(time.Second):
+				return nil, errors.New("start page not streamed")
+			}
+		}
+
+		return workingDownloader(url)
+	})
+	spider := mocks.NewSpiderMock(treeSpiderFunc(2, 2))
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithWorkers(2))
+
+	received := 0
+	for result := range runner.Stream(context.Background(), "http://example.com/n") {
+		if result.Err != nil {
+			t.Log(result.Err)
+			t.FailNow()
+		}
+
+		received++
+		if received == 1 {
+			close(firstReceived)
+		}
+	}
+
+	if received != 7 {
+		t.Logf("Wrong number of streamed resources : %d", received)
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerStreamError(t *testing.T) {
+	downloader := mocks.NewDownloaderMock(failingDownloader)
+	spider := mocks.NewSpiderMock(workingSpiderFunc)
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline)
+
+	var results []Result
+	for result := range runner.Stream(context.Background(), "http://example.com") {
+		results = append(results, result)
+	}
+
+	if len(results) != 1 || results[0].Err == nil {
+		t.Log("Stream should end with the crawl error")
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerStreamCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	downloader := mocks.NewDownloaderMock(workingDownloader)
+	spider := mocks.NewSpiderMock(treeSpiderFunc(2, 10))
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithWorkers(2))
+
+	var lastErr error
+	for result := range runner.Stream(ctx, "http://example.com/n") {
+		if result.Err != nil {
+			lastErr = result.Err
+		}
+		cancel()
+	}
+
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Logf("Wrong error : %v", lastErr)
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerStreamCancelWithoutDraining(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	downloader := mocks.NewDownloaderMock(workingDownloader)
+	spider := mocks.NewSpiderMock(treeSpiderFunc(2, 10))
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithWorkers(2))
+
+	results := runner.Stream(ctx, "http://example.com/n")
+	<-results
+	cancel()
+
+	// Stream should not wait for a reader to hand over its last result
+	deadline := time.Now().Add(time.Second)
+	for len(results) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if len(results) == 0 {
+		t.Log("Stream should buffer its last result")
+		t.FailNow()
+	}
+
+	var lastErr error
+	for result := range results {
+		lastErr = result.Err
+	}
+
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Logf("Wrong last result : %v", lastErr)
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerFailFastCrawlError(t *testing.T) {
+	downloader := mocks.NewDownloaderMock(workingDownloader)
+	spider := mocks.NewSpiderMock(workingSpiderFunc)
+	pipeline := mocks.NewPipelineMock(failingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline)
+	_, err := runner.Run("http://example.com")
+
+	var crawlError *CrawlError
+	if !errors.As(err, &crawlError) {
+		t.Logf("Wrong error : %v", err)
+		t.FailNow()
+	}
+
+	if crawlError.Stage != PipelineStage || crawlError.URL != "http://example.com" {
+		t.Logf("Wrong crawl error : %v", crawlError)
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerSkipAndRecord(t *testing.T) {
+	downloader := mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		if url == "http://example.com/n/1" {
+			return nil, errors.New("404")
+		}
+		return workingDownloader(url)
+	})
+	spider := mocks.NewSpiderMock(func(resource *domain.WebResource) ([]string, *domain.WebResource, error) {
+		if resource.URI() == "/n/0/1" {
+			return nil, nil, errors.New("unparsable")
+		}
+		return treeSpiderFunc(2, 3)(resource)
+	})
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithErrorPolicy(SkipAndRecord), WithWorkers(2))
+	resources, err := runner.Run("http://example.com/n")
+
+	var crawlErrors *CrawlErrors
+	if !errors.As(err, &crawlErrors) {
+		t.Logf("Wrong error : %v", err)
+		t.FailNow()
+	}
+
+	if len(crawlErrors.Errors) != 2 || crawlErrors.Cause != nil {
+		t.Logf("Wrong errors : %v", crawlErrors)
+		t.Fail()
+	}
+
+	stages := make(map[string]CrawlStage)
+	for _, crawlError := range crawlErrors.Errors {
+		stages[crawlError.URL] = crawlError.Stage
+	}
+
+	if stages["http://example.com/n/1"] != DownloadStage || stages["http://example.com/n/0/1"] != SpiderStage {
+		t.Logf("Wrong recorded errors : %v", crawlErrors.Errors)
+		t.Fail()
+	}
+
+	// /n, /n/0, /n/0/0 and its two children
+	if len(resources) != 5 {
+		t.Logf("Wrong number of result resources : %d", len(resources))
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerMaxErrors(t *testing.T) {
+	downloader := mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		if url != "http://example.com/n" {
+			return nil, errors.New("404")
+		}
+		return workingDownloader(url)
+	})
+	spider := mocks.NewSpiderMock(treeSpiderFunc(5, 3))
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithMaxErrors(2))
+	resources, err := runner.Run("http://example.com/n")
+
+	var crawlErrors *CrawlErrors
+	if !errors.As(err, &crawlErrors) {
+		t.Logf("Wrong error : %v", err)
+		t.FailNow()
+	}
+
+	if len(crawlErrors.Errors) != 2 {
+		t.Logf("Wrong number of errors : %d", len(crawlErrors.Errors))
+		t.Fail()
+	}
+
+	if !errors.Is(err, ErrTooManyErrors) {
+		t.Log("Crawl stopped at the threshold should tell so")
+		t.Fail()
+	}
+
+	if len(resources) != 1 {
+		t.Logf("Wrong number of result resources : %d", len(resources))
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerContextCancelRecordedErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	downloader := mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		if url == "http://example.com/n/0" {
+			return nil, errors.New("404")
+		}
+		if url == "http://example.com/n/1" {
+			cancel()
+		}
+		return workingDownloader(url)
+	})
+	spider := mocks.NewSpiderMock(treeSpiderFunc(2, 5))
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithErrorPolicy(SkipAndRecord))
+	_, err := runner.RunContext(ctx, "http://example.com/n")
+
+	var crawlErrors *CrawlErrors
+	if !errors.As(err, &crawlErrors) || len(crawlErrors.Errors) != 1 {
+		t.Logf("Recorded errors should be returned : %v", err)
+		t.FailNow()
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		t.Logf("Wrong cause : %v", crawlErrors.Cause)
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerStreamSkippedErrors(t *testing.T) {
+	downloader := mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		if url != "http://example.com/n" {
+			return nil, errors.New("404")
+		}
+		return workingDownloader(url)
+	})
+	spider := mocks.NewSpiderMock(treeSpiderFunc(3, 3))
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithErrorPolicy(SkipAndRecord))
+
+	resources := 0
+	crawlErrors := 0
+	for result := range runner.Stream(context.Background(), "http://example.com/n") {
+		var crawlError *CrawlError
+		if errors.As(result.Err, &crawlError) {
+			crawlErrors++
+		} else if result.WebResource != nil {
+			resources++
+		} else {
+			t.Logf("Unexpected result : %v", result.Err)
+			t.Fail()
+		}
+	}
+
+	if resources != 1 || crawlErrors != 3 {
+		t.Logf("Wrong streamed results : %d resources, %d errors", resources, crawlErrors)
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerPoliteness(t *testing.T) {
+	downloader := mocks.NewDownloaderMock(workingDownloader)
+	spider := mocks.NewSpiderMock(treeSpiderFunc(2, 2))
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithWorkers(4), WithPoliteness(dyzoneDownloader.WithMinDelay(10*time.Millisecond)))
+
+	start := time.Now()
+	resources, err := runner.Run("http://example.com/n")
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if len(resources) != 7 {
+		t.Logf("Wrong number of result resources : %d", len(resources))
+		t.Fail()
+	}
+
+	if elapsed < 60*time.Millisecond {
+		t.Logf("Requests to a same host were not spaced : %s", elapsed)
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerNormalizedUrls(t *testing.T) {
+	downloader := mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		// Every product page redirects to the canonical one
+		if strings.HasPrefix(url, "http://example.com/product") {
+			return workingDownloader("http://example.com/products/1")
+		}
+		return workingDownloader(url)
+	})
+	spider := mocks.NewSpiderMock(func(resource *domain.WebResource) ([]string, *domain.WebResource, error) {
+		followingUrls := []string{
+			"HTTP://Example.com:80/?utm_source=newsletter",
+			"http://example.com/a/../?fbclid=123#top",
+			"http://example.com/list?b=2&a=1",
+			"http://example.com/list?a=1&b=2",
+			"http://example.com/product?id=1",
+			"http://example.com/products/1",
+		}
+		return followingUrls, resource, nil
+	})
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline)
+	resources, err := runner.Run("http://example.com")
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	// The home page, the list and a single product page
+	if len(resources) != 3 {
+		t.Logf("Wrong number of result resources : %d", len(resources))
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerCustomNormalizer(t *testing.T) {
+	downloader := mocks.NewDownloaderMock(workingDownloader)
+	spider := mocks.NewSpiderMock(func(resource *domain.WebResource) ([]string, *domain.WebResource, error) {
+		return []string{"http://example.com/?utm_source=newsletter"}, resource, nil
+	})
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	runner := NewSpiderRunner(downloader, spider, pipeline, WithURLNormalizer(domain.NewURLNormalizer()))
+	resources, err := runner.Run("http://example.com/")
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if len(resources) != 2 {
+		t.Logf("Wrong number of result resources : %d", len(resources))
+		t.Fail()
+	}
+}
+
+func TestSpiderRunnerRobotsMeta(t *testing.T) {
+	pages := map[string]string{
+		"http://example.com/":         `<a href="/noindex">a</a><a href="/nofollow">b</a><a href="/header">c</a>`,
+		"http://example.com/noindex":  `<meta name="robots" content="noindex"><a href="/indexed">a</a>`,
+		"http://example.com/nofollow": `<meta name="ROBOTS" content="nofollow, noarchive"><a href="/hidden">a</a>`,
+		"http://example.com/header":   ``,
+		"http://example.com/indexed":  ``,
+		"http://example.com/hidden":   ``,
+	}
+	downloader := mocks.NewDownloaderMock(func(url string) (*domain.WebResource, error) {
+		resource, err := domain.NewWebResource(url, "text/html", []byte(pages[url]))
+		if err == nil && strings.HasSuffix(url, "/header") {
+			resource.ChangeResponseMetadata(domain.ResponseMetadata{Header: map[string][]string{"X-Robots-Tag": {"noindex"}}})
+		}
+		return resource, err
+	})
+	spider := mocks.NewSpiderMock(func(resource *domain.WebResource) ([]string, *domain.WebResource, error) {
+		links, err := resource.LinksUrls()
+		return links, resource, err
+	})
+	pipeline := mocks.NewPipelineMock(workingPipelineFunc)
+
+	resources, err := NewSpiderRunner(downloader, spider, pipeline).Run("http://example.com/")
+	if err != nil || len(resources) != 6 {
+		t.Logf("Without the option every page should be pipelined : %d, %v", len(resources), err)
+		t.Fail()
+	}
+
+	resources, err = NewSpiderRunner(downloader, spider, pipeline, WithRobotsMeta()).Run("http://example.com/")
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	urls := make([]string, 0)
+	for _, resource := range resources {
+		urls = append(urls, resource.FinalURL())
+	}
+
+	// noindex pages are still followed, nofollow pages are still pipelined
+	expected := []string{"http://example.com/", "http://example.com/nofollow", "http://example.com/indexed"}
+	if len(urls) != len(expected) {
+		t.Logf("Pipelined urls %s different of %s", urls, expected)
+		t.FailNow()
+	}
+	for _, url := range expected {
+		found := false
+		for _, pipelined := range urls {
+			found = found || pipelined == url
+		}
+		if !found {
+			t.Logf("Pipelined urls %s different of %s", urls, expected)
+			t.Fail()
+		}
+	}
+}
+
+// cyclingSpiderFunc simulates a navigation bar : every page links to
+// every other page of the site, including itself.
+func cyclingSpiderFunc(resource *domain.WebResource) ([]string, *domain.WebResource, error) {
+	followingUrls := []string{
+		"http://example.com/",
+		"http://EXAMPLE.com/index.html#content",
+		"http://example.com/index.html",
+		"http://example.com/about.html",
+	}
+
+	return followingUrls, resource, nil
+}
+
+// treeSpiderFunc follows a tree of urls where every page links to
+// breadth children until depth path segments below the start page.
+func treeSpiderFunc(breadth int, depth int) func(resource *domain.WebResource) ([]string, *domain.WebResource, error) {
+	return func(resource *domain.WebResource) ([]string, *domain.WebResource, error) {
+		followingUrls := []string{}
+
+		if strings.Count(resource.URI(), "/") <= depth {
+			for i := 0; i < breadth; i++ {
+				followingUrls = append(followingUrls, fmt.Sprintf("http://%s%s/%d", resource.Domain(), resource.URI(), i))
+			}
+		}
+
+		return followingUrls, resource, nil
+	}
+}
+
 func contentMatch(expected []byte, received []byte) bool {
 	if len(expected) != len(received) {
 		return false
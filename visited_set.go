@@ -0,0 +1,37 @@
+package dyzone
+
+import "sync"
+
+// VisitedSet remembers which urls have already been scheduled during a crawl
+// so that each resource is downloaded at most once. Urls are normalized
+// before being visited. Implementations must be safe for concurrent use.
+type VisitedSet interface {
+	// Visit marks the url as visited and reports whether it was unknown
+	// until now.
+	Visit(url string) bool
+}
+
+type memoryVisitedSet struct {
+	lock    sync.Mutex
+	visited map[string]struct{}
+}
+
+// NewMemoryVisitedSet returns a VisitedSet keeping urls in a map.
+// It is safe for concurrent use.
+func NewMemoryVisitedSet() VisitedSet {
+	return &memoryVisitedSet{
+		visited: make(map[string]struct{}),
+	}
+}
+
+func (set *memoryVisitedSet) Visit(url string) bool {
+	set.lock.Lock()
+	defer set.lock.Unlock()
+
+	if _, found := set.visited[url]; found {
+		return false
+	}
+
+	set.visited[url] = struct{}{}
+	return true
+}
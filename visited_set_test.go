@@ -0,0 +1,17 @@
+package dyzone
+
+import "testing"
+
+func TestMemoryVisitedSet(t *testing.T) {
+	visitedSet := NewMemoryVisitedSet()
+
+	if !visitedSet.Visit("http://example.com/") {
+		t.Log("First visit should be reported")
+		t.Fail()
+	}
+
+	if visitedSet.Visit("http://example.com/") {
+		t.Log("Second visit should not be reported")
+		t.Fail()
+	}
+}